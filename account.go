@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // SpotAccount GET /api/v1/accounts
@@ -54,3 +56,168 @@ func (kc *Kucoin) FutureAccount(currency string) (*FutureAccountData, error) {
 	}
 	return &respStruct.Data, nil
 }
+
+// SpotMarginAccount GET /api/v1/margin/account
+func (kc *Kucoin) SpotMarginAccount() (*SpotMarginAccountData, error) {
+	uri := UriSpotMarginAccount
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotMarginAccountResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotIsolatedAccounts GET /api/v1/isolated/accounts
+// balanceCurrency is the currency of the total balances, USDT, KCS or BTC. USDT as default.
+func (kc *Kucoin) SpotIsolatedAccounts(balanceCurrency string) (*SpotIsolatedAccountsData, error) {
+	uri := UriSpotIsolatedAccount
+	p := map[string]string{}
+	if len(balanceCurrency) != 0 {
+		p["balanceCurrency"] = balanceCurrency
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotIsolatedAccountsResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotLedgers GET /api/v1/accounts/ledgers
+func (kc *Kucoin) SpotLedgers(req *SpotLedgersRequest, currentPage, pageSize int) (*SpotLedgersData, error) {
+	uri := UriSpotLedgers
+	p := map[string]string{}
+	p["currentPage"] = strconv.Itoa(currentPage)
+	p["pageSize"] = strconv.Itoa(pageSize)
+	if len(req.Currency) != 0 {
+		p["currency"] = req.Currency
+	}
+	if len(req.Direction) != 0 {
+		p["direction"] = req.Direction
+	}
+	if len(req.BizType) != 0 {
+		p["bizType"] = req.BizType
+	}
+	if req.StartAt != 0 {
+		p["startAt"] = strconv.FormatInt(req.StartAt, 10)
+	}
+	if req.EndAt != 0 {
+		p["endAt"] = strconv.FormatInt(req.EndAt, 10)
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotLedgersResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotBaseFee GET /api/v1/base-fee
+func (kc *Kucoin) SpotBaseFee() (*SpotBaseFeeData, error) {
+	uri := UriSpotBaseFee
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotBaseFeeResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// spotTradeFeesBatch the maximum number of symbols of a single GET /api/v1/trade-fees request
+const spotTradeFeesBatch = 10
+
+// SpotTradeFees GET /api/v1/trade-fees
+// The symbols are split into batches of 10, one request per batch.
+func (kc *Kucoin) SpotTradeFees(symbols ...string) ([]TradeFeeData, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("symbols is empty")
+	}
+
+	result := make([]TradeFeeData, 0, len(symbols))
+	for i := 0; i < len(symbols); i += spotTradeFeesBatch {
+		end := i + spotTradeFeesBatch
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		data, err := kc.spotTradeFees(symbols[i:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data...)
+	}
+	return result, nil
+}
+
+func (kc *Kucoin) spotTradeFees(symbols []string) ([]TradeFeeData, error) {
+	uri := UriSpotTradeFees
+	p := map[string]string{}
+	p["symbols"] = strings.Join(symbols, ",")
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotTradeFeesResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// FutureTradeFee GET /api/v1/trade-fees
+func (kc *Kucoin) FutureTradeFee(symbol string) (*TradeFeeData, error) {
+	uri := UriFutureTradeFee
+	p := map[string]string{}
+	p["symbol"] = symbol
+
+	resp, err := kc.do(kc.futureEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &FutureTradeFeeResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
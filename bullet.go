@@ -0,0 +1,50 @@
+package kugo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// SpotBulletPublic POST /api/v1/bullet-public
+// Apply for a token of the public WebSocket channels.
+func (kc *Kucoin) SpotBulletPublic() (*BulletData, error) {
+	return kc.bullet(kc.spotEndpoint, UriSpotBulletPublic)
+}
+
+// SpotBulletPrivate POST /api/v1/bullet-private
+// Apply for a token of the public and private WebSocket channels. The API key is required.
+func (kc *Kucoin) SpotBulletPrivate() (*BulletData, error) {
+	return kc.bullet(kc.spotEndpoint, UriSpotBulletPrivate)
+}
+
+// FutureBulletPublic POST /api/v1/bullet-public
+// Apply for a token of the public WebSocket channels.
+func (kc *Kucoin) FutureBulletPublic() (*BulletData, error) {
+	return kc.bullet(kc.futureEndpoint, UriFutureBulletPublic)
+}
+
+// FutureBulletPrivate POST /api/v1/bullet-private
+// Apply for a token of the public and private WebSocket channels. The API key is required.
+func (kc *Kucoin) FutureBulletPrivate() (*BulletData, error) {
+	return kc.bullet(kc.futureEndpoint, UriFutureBulletPrivate)
+}
+
+func (kc *Kucoin) bullet(endpoint, uri string) (*BulletData, error) {
+	resp, err := kc.do(endpoint, http.MethodPost, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &BulletResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	if len(respStruct.Data.InstanceServers) == 0 {
+		return nil, errors.New("no instance server available")
+	}
+	return &respStruct.Data, nil
+}
@@ -0,0 +1,586 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Types of bars
+const (
+	BarTime   = "time"   // Bars of a fixed interval
+	BarVolume = "volume" // Bars closed after a volume of the base currency is traded
+	BarDollar = "dollar" // Bars closed after a value of the quote currency is traded
+)
+
+// BarSpec How the trades are grouped into bars
+type BarSpec struct {
+	Type      string
+	Interval  time.Duration   // Interval of time bars
+	Threshold decimal.Decimal // Volume of volume bars or value of dollar bars
+}
+
+// TimeBars Bars of a fixed interval of at least 1ms, aligned to the unix epoch. e.g. 5s, 15s, 3m.
+// Intervals of whole weeks are aligned to Monday like the weekly klines
+func TimeBars(interval time.Duration) BarSpec {
+	return BarSpec{Type: BarTime, Interval: interval}
+}
+
+// VolumeBars Bars closed after the volume of the base currency reaches threshold
+func VolumeBars(threshold decimal.Decimal) BarSpec {
+	return BarSpec{Type: BarVolume, Threshold: threshold}
+}
+
+// DollarBars Bars closed after the value of the quote currency reaches threshold
+func DollarBars(threshold decimal.Decimal) BarSpec {
+	return BarSpec{Type: BarDollar, Threshold: threshold}
+}
+
+// minBarInterval The minimum interval of time bars
+const minBarInterval = time.Millisecond
+
+// weekOffset The unix epoch is a Thursday, weeks start 4 days later on Monday
+const weekOffset = int64(4 * 24 * time.Hour)
+
+// barStart Return the start of the bar of the interval containing t, in nanoseconds
+func barStart(t int64, interval time.Duration) int64 {
+	var offset int64
+	if interval%(7*24*time.Hour) == 0 {
+		offset = weekOffset
+	}
+	d := t - offset
+	start := d / int64(interval) * int64(interval)
+	if start > d {
+		start -= int64(interval)
+	}
+	return start + offset
+}
+
+func (s BarSpec) validate() error {
+	switch s.Type {
+	case BarTime:
+		if s.Interval < minBarInterval {
+			return fmt.Errorf("interval must be at least %s", minBarInterval)
+		}
+	case BarVolume, BarDollar:
+		if !s.Threshold.IsPositive() {
+			return errors.New("threshold must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown bar type %q", s.Type)
+	}
+	return nil
+}
+
+// Tick A trade fed to the CandleAggregator
+type Tick struct {
+	Time  int64           // nanosecond
+	Price decimal.Decimal // Price in the quote currency
+	Size  decimal.Decimal // Size in the base currency
+	Value decimal.Decimal // Value in the quote currency, Price * Size if it is zero
+}
+
+// Bar An OHLCV bar
+type Bar struct {
+	Symbol   string
+	Start    int64 // nanosecond. The start of the interval for time bars, the first trade otherwise
+	End      int64 // nanosecond. The end of the interval for time bars, the last trade otherwise
+	Open     decimal.Decimal
+	High     decimal.Decimal
+	Low      decimal.Decimal
+	Close    decimal.Decimal
+	Volume   decimal.Decimal // Volume of the base currency
+	Turnover decimal.Decimal // Value of the quote currency
+	Trades   int
+	Amended  bool // The closed bar is amended by a late trade
+}
+
+// CandleOption Option of CandleAggregator
+type CandleOption func(a *CandleAggregator) error
+
+// SetCandleHandler Set the handler called with every closed or amended bar.
+// It is called in the goroutine of the WebSocket client or the timer, so it should return quickly
+func SetCandleHandler(handler func(bar *Bar)) CandleOption {
+	return func(a *CandleAggregator) error {
+		if a == nil {
+			return errors.New("aggregator is nil")
+		}
+		a.handler = handler
+		return nil
+	}
+}
+
+// SetCandleLateness Set how long after the latest trade a late trade is still accepted, 0 by default.
+// A late trade of a closed time bar amends the bar, a later one is dropped and counted by Dropped.
+func SetCandleLateness(lateness time.Duration) CandleOption {
+	return func(a *CandleAggregator) error {
+		if a == nil {
+			return errors.New("aggregator is nil")
+		}
+		if lateness < 0 {
+			return errors.New("lateness must not be negative")
+		}
+		a.lateness = int64(lateness)
+		return nil
+	}
+}
+
+// SetCandleHistory Set the number of closed bars kept by the aggregator, 1000 by default
+func SetCandleHistory(n int) CandleOption {
+	return func(a *CandleAggregator) error {
+		if a == nil {
+			return errors.New("aggregator is nil")
+		}
+		if n <= 0 {
+			return errors.New("history must be positive")
+		}
+		a.history = n
+		return nil
+	}
+}
+
+// CandleAggregator Build bars of arbitrary intervals, volume or value from the trade streams.
+// Time bars are closed when a trade of a later interval arrives or the interval has passed.
+// Volume and dollar bars are closed by the trade reaching the threshold, which is not split.
+// It is safe for concurrent use.
+type CandleAggregator struct {
+	symbol    string
+	spec      BarSpec
+	ws        *WsClient
+	topic     string
+	subscribe func() error
+	klines    func(from, to time.Time) ([]Bar, error)
+
+	handler  func(bar *Bar)
+	lateness int64
+	history  int
+
+	mu        sync.Mutex
+	current   *Bar
+	bars      []Bar // Closed bars sorted by start
+	latest    int64 // Time of the latest trade
+	watermark int64 // Trades before it are included in the bootstrapped bars
+	dropped   int64
+	closed    chan struct{}
+}
+
+// NewSpotCandleAggregator Create an aggregator of the spot symbol fed by /market/match
+func NewSpotCandleAggregator(kc *Kucoin, ws *WsClient, symbol string, spec BarSpec, opts ...CandleOption) (*CandleAggregator, error) {
+	a, err := newCandleAggregator(ws, symbol, fmt.Sprintf(TopicSpotMatch, symbol), spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	a.subscribe = func() error {
+		return ws.SubscribeSpotMatch(func(e *SpotMatchEvent) {
+			a.Add(Tick{Time: e.Time, Price: e.Price, Size: e.Size})
+		}, symbol)
+	}
+	a.klines = func(from, to time.Time) ([]Bar, error) {
+		return spotKlineBars(kc, symbol, spec.Interval, from, to)
+	}
+	return a, nil
+}
+
+// NewFutureCandleAggregator Create an aggregator of the contract fed by /contractMarket/execution.
+// The sizes of the contract are converted to the base currency with the multiplier of the contract.
+func NewFutureCandleAggregator(kc *Kucoin, ws *WsClient, contract *FutureSymbolData, spec BarSpec, opts ...CandleOption) (*CandleAggregator, error) {
+	symbol := contract.Symbol
+	a, err := newCandleAggregator(ws, symbol, fmt.Sprintf(TopicFutureExecution, symbol), spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	multiplier, inverse := contract.Multiplier.Abs(), contract.IsInverse
+	a.subscribe = func() error {
+		return ws.SubscribeFutureExecution(func(e *FutureExecutionEvent) {
+			a.Add(futureTick(e.Ts, e.Price, decimal.NewFromInt(int64(e.Size)), multiplier, inverse))
+		}, symbol)
+	}
+	a.klines = func(from, to time.Time) ([]Bar, error) {
+		return futureKlineBars(kc, symbol, spec.Interval, multiplier, inverse, from, to)
+	}
+	return a, nil
+}
+
+// futureTick Convert contracts to the size of the base currency and the value of the quote currency.
+// Inverse contracts are valued in the quote currency.
+func futureTick(ts int64, price, contracts, multiplier decimal.Decimal, inverse bool) Tick {
+	if inverse {
+		value := contracts.Mul(multiplier)
+		size := decimal.Zero
+		if price.IsPositive() {
+			size = value.Div(price)
+		}
+		return Tick{Time: ts, Price: price, Size: size, Value: value}
+	}
+	size := contracts.Mul(multiplier)
+	return Tick{Time: ts, Price: price, Size: size, Value: size.Mul(price)}
+}
+
+func newCandleAggregator(ws *WsClient, symbol, topic string, spec BarSpec, opts []CandleOption) (*CandleAggregator, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	a := &CandleAggregator{
+		symbol:  symbol,
+		spec:    spec,
+		ws:      ws,
+		topic:   topic,
+		history: 1000,
+		closed:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Bootstrap Load the closed time bars between from and to from the REST klines API.
+// The interval must be a multiple of a kline granularity. Call it before Start,
+// the trades before the last loaded bar are treated as loaded and dropped.
+func (a *CandleAggregator) Bootstrap(from, to time.Time) error {
+	if a.spec.Type != BarTime {
+		return errors.New("only time bars can be bootstrapped")
+	}
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	// Only the intervals which have passed are loaded
+	end := barStart(to.UnixNano(), a.spec.Interval)
+	bars, err := a.klines(from, time.Unix(0, end))
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range bars {
+		bars[i].Symbol = a.symbol
+	}
+	a.bars = append(bars, a.bars...)
+	a.trim()
+	if end > a.watermark {
+		a.watermark = end
+	}
+	if end > a.latest {
+		a.latest = end
+	}
+	return nil
+}
+
+// Start Subscribe to the trade stream
+func (a *CandleAggregator) Start() error {
+	if err := a.subscribe(); err != nil {
+		return err
+	}
+	if a.spec.Type == BarTime {
+		go a.timer()
+	}
+	return nil
+}
+
+// Close Unsubscribe from the trade stream
+func (a *CandleAggregator) Close() error {
+	a.mu.Lock()
+	select {
+	case <-a.closed:
+		a.mu.Unlock()
+		return nil
+	default:
+	}
+	close(a.closed)
+	a.mu.Unlock()
+	return a.ws.Unsubscribe(a.topic)
+}
+
+// Symbol Return the symbol of the aggregator
+func (a *CandleAggregator) Symbol() string {
+	return a.symbol
+}
+
+// Current Return a copy of the open bar
+func (a *CandleAggregator) Current() (Bar, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil {
+		return Bar{}, false
+	}
+	return *a.current, true
+}
+
+// Bars Return copies of the closed bars, the oldest first
+func (a *CandleAggregator) Bars() []Bar {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	result := make([]Bar, len(a.bars))
+	copy(result, a.bars)
+	return result
+}
+
+// Dropped Return the number of trades dropped for being too late
+func (a *CandleAggregator) Dropped() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// Add Feed a trade to the aggregator. The streams of Start feed it automatically
+func (a *CandleAggregator) Add(tick Tick) {
+	if tick.Value.IsZero() {
+		tick.Value = tick.Price.Mul(tick.Size)
+	}
+	a.mu.Lock()
+	var emit []*Bar
+	switch {
+	case tick.Time < a.watermark:
+		// Included in the bootstrapped bars
+	case tick.Time < a.latest-a.lateness:
+		a.dropped++
+	case a.spec.Type == BarTime:
+		emit = a.addTime(tick)
+	default:
+		emit = a.addThreshold(tick)
+	}
+	if tick.Time > a.latest {
+		a.latest = tick.Time
+	}
+	handler := a.handler
+	a.mu.Unlock()
+	a.emit(handler, emit)
+}
+
+// addTime a.mu must be held
+func (a *CandleAggregator) addTime(tick Tick) []*Bar {
+	interval := int64(a.spec.Interval)
+	start := barStart(tick.Time, a.spec.Interval)
+	var emit []*Bar
+	switch {
+	case a.current != nil && start == a.current.Start:
+		a.current.add(tick)
+	case a.current != nil && start > a.current.Start,
+		a.current == nil && (len(a.bars) == 0 || start > a.bars[len(a.bars)-1].Start):
+		if a.current != nil {
+			emit = append(emit, a.close())
+		}
+		a.current = &Bar{Symbol: a.symbol, Start: start, End: start + interval}
+		a.current.add(tick)
+	default:
+		// A late trade of a closed bar
+		i := sort.Search(len(a.bars), func(i int) bool { return a.bars[i].Start >= start })
+		if i == len(a.bars) || a.bars[i].Start != start {
+			a.bars = append(a.bars, Bar{})
+			copy(a.bars[i+1:], a.bars[i:])
+			a.bars[i] = Bar{Symbol: a.symbol, Start: start, End: start + interval}
+		}
+		a.bars[i].add(tick)
+		a.bars[i].Amended = true
+		bar := a.bars[i]
+		emit = append(emit, &bar)
+		a.trim()
+	}
+	return emit
+}
+
+// addThreshold a.mu must be held
+func (a *CandleAggregator) addThreshold(tick Tick) []*Bar {
+	if a.current == nil {
+		a.current = &Bar{Symbol: a.symbol, Start: tick.Time}
+	}
+	a.current.add(tick)
+	total := a.current.Volume
+	if a.spec.Type == BarDollar {
+		total = a.current.Turnover
+	}
+	if total.LessThan(a.spec.Threshold) {
+		return nil
+	}
+	bar := a.close()
+	a.current = nil
+	return []*Bar{bar}
+}
+
+// close Move the open bar to the closed bars. a.mu must be held
+func (a *CandleAggregator) close() *Bar {
+	bar := *a.current
+	a.bars = append(a.bars, bar)
+	a.trim()
+	return &bar
+}
+
+func (a *CandleAggregator) trim() {
+	if n := len(a.bars) - a.history; n > 0 {
+		a.bars = append(a.bars[:0], a.bars[n:]...)
+	}
+}
+
+// timer Close the open time bar after its interval has passed, even if no trade arrives
+func (a *CandleAggregator) timer() {
+	ticker := time.NewTicker(a.spec.Interval / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.closed:
+			return
+		case now := <-ticker.C:
+			a.mu.Lock()
+			var emit []*Bar
+			if a.current != nil && now.UnixNano() >= a.current.End {
+				emit = append(emit, a.close())
+				a.current = nil
+			}
+			handler := a.handler
+			a.mu.Unlock()
+			a.emit(handler, emit)
+		}
+	}
+}
+
+func (a *CandleAggregator) emit(handler func(bar *Bar), bars []*Bar) {
+	if handler == nil {
+		return
+	}
+	for _, bar := range bars {
+		handler(bar)
+	}
+}
+
+func (b *Bar) add(tick Tick) {
+	if b.Trades == 0 {
+		b.Open, b.High, b.Low = tick.Price, tick.Price, tick.Price
+	}
+	if tick.Price.GreaterThan(b.High) {
+		b.High = tick.Price
+	}
+	if tick.Price.LessThan(b.Low) {
+		b.Low = tick.Price
+	}
+	b.Close = tick.Price
+	b.Volume = b.Volume.Add(tick.Size)
+	b.Turnover = b.Turnover.Add(tick.Value)
+	b.Trades++
+	if b.End < tick.Time {
+		b.End = tick.Time
+	}
+}
+
+// merge Merge the following candle into the bar
+func (b *Bar) merge(c Bar) {
+	if b.Open.IsZero() {
+		b.Open, b.High, b.Low = c.Open, c.High, c.Low
+	}
+	if c.High.GreaterThan(b.High) {
+		b.High = c.High
+	}
+	if c.Low.LessThan(b.Low) {
+		b.Low = c.Low
+	}
+	b.Close = c.Close
+	b.Volume = b.Volume.Add(c.Volume)
+	b.Turnover = b.Turnover.Add(c.Turnover)
+	b.Trades += c.Trades
+}
+
+// Granularities of the klines APIs
+var (
+	spotKlineTypes = []struct {
+		interval time.Duration
+		name     string
+	}{
+		{7 * 24 * time.Hour, "1week"}, {24 * time.Hour, "1day"}, {12 * time.Hour, "12hour"},
+		{8 * time.Hour, "8hour"}, {6 * time.Hour, "6hour"}, {4 * time.Hour, "4hour"},
+		{2 * time.Hour, "2hour"}, {time.Hour, "1hour"}, {30 * time.Minute, "30min"},
+		{15 * time.Minute, "15min"}, {5 * time.Minute, "5min"}, {3 * time.Minute, "3min"},
+		{time.Minute, "1min"},
+	}
+	futureKlineGranularities = []int{10080, 1440, 720, 480, 240, 120, 60, 30, 15, 5, 1}
+)
+
+const (
+	spotKlinesLimit   = 1500
+	futureKlinesLimit = 200
+)
+
+// spotKlineBars Load the klines of the largest granularity dividing interval and aggregate them
+func spotKlineBars(kc *Kucoin, symbol string, interval time.Duration, from, to time.Time) ([]Bar, error) {
+	for _, t := range spotKlineTypes {
+		if interval%t.interval != 0 {
+			continue
+		}
+		var bars []Bar
+		step := int64(t.interval/time.Second) * spotKlinesLimit
+		for start := from.Unix(); start < to.Unix(); start += step {
+			end := start + step
+			if end > to.Unix() {
+				end = to.Unix()
+			}
+			candles, err := kc.SpotKlines(symbol, t.name, start, end)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range candles {
+				bars = append(bars, Bar{
+					Start: c.Time * int64(time.Second), End: c.Time*int64(time.Second) + int64(t.interval),
+					Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume, Turnover: c.Turnover,
+				})
+			}
+		}
+		return groupBars(bars, interval, from, to), nil
+	}
+	return nil, fmt.Errorf("interval %s is not a multiple of any kline granularity", interval)
+}
+
+// futureKlineBars Load the klines of the largest granularity dividing interval and aggregate them
+func futureKlineBars(kc *Kucoin, symbol string, interval time.Duration, multiplier decimal.Decimal, inverse bool, from, to time.Time) ([]Bar, error) {
+	for _, granularity := range futureKlineGranularities {
+		g := time.Duration(granularity) * time.Minute
+		if interval%g != 0 {
+			continue
+		}
+		var bars []Bar
+		step := g.Milliseconds() * futureKlinesLimit
+		for start := from.UnixMilli(); start < to.UnixMilli(); start += step {
+			end := start + step
+			if end > to.UnixMilli() {
+				end = to.UnixMilli()
+			}
+			candles, err := kc.FutureKlines(symbol, granularity, start, end)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range candles {
+				// The value of a candle is estimated with the close price
+				tick := futureTick(0, c.Close, c.Volume, multiplier, inverse)
+				bars = append(bars, Bar{
+					Start: c.Time * int64(time.Millisecond), End: c.Time*int64(time.Millisecond) + int64(g),
+					Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: tick.Size, Turnover: tick.Value,
+				})
+			}
+		}
+		return groupBars(bars, interval, from, to), nil
+	}
+	return nil, fmt.Errorf("interval %s is not a multiple of any kline granularity", interval)
+}
+
+// groupBars Merge the klines into bars of the interval. Klines outside [from, to) are skipped
+func groupBars(klines []Bar, interval time.Duration, from, to time.Time) []Bar {
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Start < klines[j].Start })
+	var bars []Bar
+	lastKline := int64(-1)
+	for _, k := range klines {
+		if k.Start < from.UnixNano() || k.End > to.UnixNano() || k.Start == lastKline {
+			// Out of range, or repeated at the boundary of two requests
+			continue
+		}
+		lastKline = k.Start
+		start := barStart(k.Start, interval)
+		if len(bars) == 0 || bars[len(bars)-1].Start != start {
+			bars = append(bars, Bar{Start: start, End: start + int64(interval)})
+		}
+		bars[len(bars)-1].merge(k)
+	}
+	return bars
+}
@@ -0,0 +1,246 @@
+package kugo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOrderNotFound is returned when there is no order of the clientOid
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderNotPlaced is returned by PlaceOrderIdempotent when the request timed out
+	// and no order of the clientOid was found before ctx was done. The order may still be
+	// accepted later, so place it again only with the same clientOid, e.g. with the same request
+	ErrOrderNotPlaced = errors.New("order not placed")
+	// ErrOrderOutcomeUnknown is returned by PlaceOrderIdempotent when the request timed out
+	// and the order could not be looked up. Look it up by the clientOid before placing it again
+	ErrOrderOutcomeUnknown = errors.New("order outcome unknown")
+)
+
+// maxClientOidLen The max length of a clientOid accepted by Kucoin
+const maxClientOidLen = 40
+
+const (
+	idempotentLookupWindow = 5 * time.Second        // How long an order is looked up after a timeout if ctx has no deadline
+	idempotentLookupDelay  = 500 * time.Millisecond // The delay before each lookup
+)
+
+// SetAutoClientOid Generate the clientOid of SpotOrder, SpotMarginOrder and FutureOrder when it is empty.
+// The clientOid is prefix followed by a random UUID of 32 hex digits, so prefix is up to 8 characters
+func SetAutoClientOid(prefix string) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		if len(prefix)+32 > maxClientOidLen {
+			return fmt.Errorf("prefix %s is too long", prefix)
+		}
+		kc.clientOidPrefix = prefix
+		kc.clientOidGen = newUUID
+		return nil
+	}
+}
+
+// SetClientOidGenerator Generate the clientOid of SpotOrder, SpotMarginOrder and FutureOrder
+// with gen when it is empty. The clientOid is prefix followed by the result of gen,
+// which must be unique and up to 40 characters in total
+func SetClientOidGenerator(prefix string, gen func() string) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		if gen == nil {
+			return errors.New("generator is nil")
+		}
+		kc.clientOidPrefix = prefix
+		kc.clientOidGen = gen
+		return nil
+	}
+}
+
+// newUUID Return a random (version 4) UUID without hyphens
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return hex.EncodeToString(b[:])
+}
+
+// newClientOid Generate a clientOid with the generator of the instance, or a UUID if it is not set
+func (kc *Kucoin) newClientOid() (string, error) {
+	gen := kc.clientOidGen
+	if gen == nil {
+		gen = newUUID
+	}
+	clientOid := kc.clientOidPrefix + gen()
+	if len(clientOid) > maxClientOidLen {
+		return "", fmt.Errorf("clientOid %s is longer than %d", clientOid, maxClientOidLen)
+	}
+	return clientOid, nil
+}
+
+// fillClientOid Generate the clientOid if it is empty and the generation is enabled
+func (kc *Kucoin) fillClientOid(clientOid *string) error {
+	if *clientOid != "" || kc.clientOidGen == nil {
+		return nil
+	}
+	id, err := kc.newClientOid()
+	if err != nil {
+		return err
+	}
+	*clientOid = id
+	return nil
+}
+
+// PlacedOrder Result of PlaceOrderIdempotent
+type PlacedOrder struct {
+	OrderId   string
+	ClientOid string
+	Resolved  bool // The request timed out and the order was found by the clientOid
+}
+
+// PlaceOrderIdempotent Place an order of *SpotOrdersRequest, *SpotMarginOrderRequest or *FutureOrderRequest.
+// A clientOid is generated if it is empty, even if SetAutoClientOid is not set, and kept in req.
+// If the clientOid is set, e.g. when placing the request again, the order is looked up first and
+// returned if it exists, so that it is not placed twice.
+// If the request times out, the order is looked up by the clientOid until ctx is done, or for 5 seconds
+// if ctx has no deadline: the order is returned if it is found, ErrOrderNotPlaced if it is not,
+// and ErrOrderOutcomeUnknown if the lookups fail
+func (kc *Kucoin) PlaceOrderIdempotent(ctx context.Context, req interface{}) (*PlacedOrder, error) {
+	var clientOid *string
+	var place func() (string, error)
+	var lookup func(clientOid string) (string, error)
+	switch r := req.(type) {
+	case *SpotOrdersRequest:
+		clientOid = &r.ClientOid
+		place = func() (string, error) {
+			data, err := kc.SpotOrder(r)
+			if err != nil {
+				return "", err
+			}
+			return data.OrderId, nil
+		}
+		lookup = kc.spotOrderIdByClientOid
+	case *SpotMarginOrderRequest:
+		clientOid = &r.ClientOid
+		place = func() (string, error) {
+			data, err := kc.SpotMarginOrder(r)
+			if err != nil {
+				return "", err
+			}
+			return data.OrderId, nil
+		}
+		lookup = kc.spotOrderIdByClientOid
+	case *FutureOrderRequest:
+		clientOid = &r.ClientOid
+		place = func() (string, error) {
+			data, err := kc.FutureOrder(r)
+			if err != nil {
+				return "", err
+			}
+			return data.OrderId, nil
+		}
+		lookup = func(clientOid string) (string, error) {
+			data, err := kc.FutureOrderByClientOid(clientOid)
+			if err != nil {
+				return "", err
+			}
+			return data.Id, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported order request %T", req)
+	}
+
+	if *clientOid == "" {
+		id, err := kc.newClientOid()
+		if err != nil {
+			return nil, err
+		}
+		*clientOid = id
+	} else {
+		// An earlier attempt of the clientOid may have been accepted after all
+		orderId, err := lookup(*clientOid)
+		if err == nil {
+			return &PlacedOrder{OrderId: orderId, ClientOid: *clientOid, Resolved: true}, nil
+		}
+		if !errors.Is(err, ErrOrderNotFound) {
+			return nil, err
+		}
+	}
+	orderId, err := place()
+	if err == nil {
+		return &PlacedOrder{OrderId: orderId, ClientOid: *clientOid}, nil
+	}
+	if !isTimeout(err) {
+		return nil, err
+	}
+
+	// The order may take a while to be found after the timeout
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, idempotentLookupWindow)
+		defer cancel()
+	}
+	orderId, lookupErr := lookupOrder(ctx, lookup, *clientOid)
+	if lookupErr == nil {
+		return &PlacedOrder{OrderId: orderId, ClientOid: *clientOid, Resolved: true}, nil
+	}
+	if errors.Is(lookupErr, ErrOrderNotFound) {
+		return nil, fmt.Errorf("%w: clientOid %s: %v", ErrOrderNotPlaced, *clientOid, err)
+	}
+	return nil, fmt.Errorf("%w: clientOid %s: %v, lookup: %v", ErrOrderOutcomeUnknown, *clientOid, err, lookupErr)
+}
+
+// lookupOrder Look up the order by the clientOid until it is found or ctx is done.
+// Return the error of the last lookup, or of ctx if the order was not looked up
+func lookupOrder(ctx context.Context, lookup func(clientOid string) (string, error), clientOid string) (string, error) {
+	var err error
+	for {
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return "", err
+		case <-time.After(idempotentLookupDelay):
+		}
+		orderId, e := lookup(clientOid)
+		if e == nil {
+			return orderId, nil
+		}
+		err = e
+	}
+}
+
+func (kc *Kucoin) spotOrderIdByClientOid(clientOid string) (string, error) {
+	data, err := kc.SpotOrderByClientOid(clientOid)
+	if err != nil {
+		return "", err
+	}
+	return data.Id, nil
+}
+
+// orderNotFoundError Return ErrOrderNotFound if the error message of a lookup by clientOid means that
+// the order does not exist, e.g. "order_not_exist" or "The order does not exist", otherwise an error of msg
+func orderNotFoundError(clientOid, msg string) error {
+	m := strings.ToLower(strings.ReplaceAll(msg, "_", " "))
+	if strings.Contains(m, "not exist") || strings.Contains(m, "not found") {
+		return fmt.Errorf("%w: clientOid %s: %s", ErrOrderNotFound, clientOid, msg)
+	}
+	return errors.New(msg)
+}
+
+// isTimeout Return whether the request timed out, in which case the outcome of the request is unknown
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout() || errors.Is(err, context.DeadlineExceeded)
+}
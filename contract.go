@@ -0,0 +1,287 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strconv"
+)
+
+// ErrQuantoContract is returned when converting the sizes of a quanto contract,
+// whose value depends on the exchange rate of the settle currency
+var ErrQuantoContract = errors.New("quanto contracts are not supported")
+
+// Linear contracts are sized in the base currency: a contract is Multiplier base currency,
+// e.g. 0.001 XBT of XBTUSDTM. Inverse contracts are sized in the quote currency: a contract
+// is |Multiplier| quote currency, e.g. 1 USD of XBTUSDM.
+
+// BaseQty Return the quantity of the base currency of the contracts at the price
+func (s *FutureSymbolData) BaseQty(contracts, price decimal.Decimal) (decimal.Decimal, error) {
+	if err := s.checkConvertible(); err != nil {
+		return decimal.Zero, err
+	}
+	if !s.IsInverse {
+		return contracts.Mul(s.Multiplier.Abs()), nil
+	}
+	if !price.IsPositive() {
+		return decimal.Zero, fmt.Errorf("invalid price %s", price)
+	}
+	return contracts.Mul(s.Multiplier.Abs()).Div(price), nil
+}
+
+// Notional Return the value of the contracts in the quote currency at the price
+func (s *FutureSymbolData) Notional(contracts, price decimal.Decimal) (decimal.Decimal, error) {
+	if err := s.checkConvertible(); err != nil {
+		return decimal.Zero, err
+	}
+	if s.IsInverse {
+		return contracts.Mul(s.Multiplier.Abs()), nil
+	}
+	if !price.IsPositive() {
+		return decimal.Zero, fmt.Errorf("invalid price %s", price)
+	}
+	return contracts.Mul(s.Multiplier.Abs()).Mul(price), nil
+}
+
+// ContractsForBase Return the contracts of the quantity of the base currency at the price,
+// rounded down to the lot size
+func (s *FutureSymbolData) ContractsForBase(qty, price decimal.Decimal) (int, error) {
+	// The value of one contract in the base currency
+	one, err := s.BaseQty(decimal.NewFromInt(1), price)
+	if err != nil {
+		return 0, err
+	}
+	return s.contracts(qty, one)
+}
+
+// ContractsForNotional Return the contracts of the value in the quote currency at the price,
+// rounded down to the lot size
+func (s *FutureSymbolData) ContractsForNotional(notional, price decimal.Decimal) (int, error) {
+	// The value of one contract in the quote currency
+	one, err := s.Notional(decimal.NewFromInt(1), price)
+	if err != nil {
+		return 0, err
+	}
+	return s.contracts(notional, one)
+}
+
+func (s *FutureSymbolData) contracts(amount, one decimal.Decimal) (int, error) {
+	if !one.IsPositive() {
+		return 0, fmt.Errorf("invalid multiplier %s of %s", s.Multiplier, s.Symbol)
+	}
+	return int(floorStep(amount.Div(one).Floor(), s.LotSize).IntPart()), nil
+}
+
+func (s *FutureSymbolData) checkConvertible() error {
+	if s.IsQuanto {
+		return fmt.Errorf("%w: %s", ErrQuantoContract, s.Symbol)
+	}
+	if s.Multiplier.IsZero() {
+		return fmt.Errorf("invalid multiplier %s of %s", s.Multiplier, s.Symbol)
+	}
+	return nil
+}
+
+// FutureOrderBuilder Build a FutureOrderRequest sized in contracts, base quantity or quote notional.
+// Base quantity and notional are converted to contracts at the limit price, or at the reference
+// price for market orders, and rounded down to the lot size.
+// Build validates the fields which exclude each other like SpotOrderBuilder.
+type FutureOrderBuilder struct {
+	contract    *FutureSymbolData
+	req         FutureOrderRequest
+	refPrice    decimal.Decimal
+	baseQty     decimal.Decimal
+	notional    decimal.Decimal
+	sized       bool
+	visibleSize int
+}
+
+// NewFutureOrderBuilder Create a builder of an order of the contract
+func NewFutureOrderBuilder(contract *FutureSymbolData, side Side) *FutureOrderBuilder {
+	return &FutureOrderBuilder{
+		contract: contract,
+		req:      FutureOrderRequest{Symbol: contract.Symbol, Side: side},
+	}
+}
+
+// NewFutureLimit Create a builder of a limit order of size contracts at price.
+// It has no contract data, so it can not be sized in base quantity or notional
+func NewFutureLimit(symbol string, side Side, price decimal.Decimal, size int) *FutureOrderBuilder {
+	b := &FutureOrderBuilder{req: FutureOrderRequest{Symbol: symbol, Side: side}}
+	return b.Limit(price).Contracts(size)
+}
+
+// NewFutureMarket Create a builder of a market order of size contracts.
+// It has no contract data, so it can not be sized in base quantity or notional
+func NewFutureMarket(symbol string, side Side, size int) *FutureOrderBuilder {
+	b := &FutureOrderBuilder{req: FutureOrderRequest{Symbol: symbol, Side: side}}
+	return b.Market(decimal.Zero).Contracts(size)
+}
+
+// Limit Make it a limit order at the price
+func (b *FutureOrderBuilder) Limit(price decimal.Decimal) *FutureOrderBuilder {
+	b.req.Type = OrderTypeLimit
+	b.req.Price = price
+	return b
+}
+
+// Market Make it a market order. refPrice is used to convert base quantity or notional to contracts
+func (b *FutureOrderBuilder) Market(refPrice decimal.Decimal) *FutureOrderBuilder {
+	b.req.Type = OrderTypeMarket
+	b.req.Price = decimal.Zero
+	b.refPrice = refPrice
+	return b
+}
+
+// Contracts Size the order in contracts
+func (b *FutureOrderBuilder) Contracts(n int) *FutureOrderBuilder {
+	b.req.Size, b.baseQty, b.notional, b.sized = n, decimal.Zero, decimal.Zero, true
+	return b
+}
+
+// BaseQty Size the order in the base currency
+func (b *FutureOrderBuilder) BaseQty(qty decimal.Decimal) *FutureOrderBuilder {
+	b.req.Size, b.baseQty, b.notional, b.sized = 0, qty, decimal.Zero, true
+	return b
+}
+
+// Notional Size the order in the quote currency
+func (b *FutureOrderBuilder) Notional(value decimal.Decimal) *FutureOrderBuilder {
+	b.req.Size, b.baseQty, b.notional, b.sized = 0, decimal.Zero, value, true
+	return b
+}
+
+// Leverage Set the leverage of the order
+func (b *FutureOrderBuilder) Leverage(leverage decimal.Decimal) *FutureOrderBuilder {
+	b.req.Leverage = leverage
+	return b
+}
+
+// ReduceOnly Only reduce the position
+func (b *FutureOrderBuilder) ReduceOnly() *FutureOrderBuilder {
+	b.req.ReduceOnly = true
+	return b
+}
+
+// ClientOid Set the client order id
+func (b *FutureOrderBuilder) ClientOid(clientOid string) *FutureOrderBuilder {
+	b.req.ClientOid = clientOid
+	return b
+}
+
+// Remark Set the remark of the order
+func (b *FutureOrderBuilder) Remark(remark string) *FutureOrderBuilder {
+	b.req.Remark = remark
+	return b
+}
+
+// GTC Good till canceled
+func (b *FutureOrderBuilder) GTC() *FutureOrderBuilder {
+	b.req.TimeInForce = TimeInForceGTC
+	return b
+}
+
+// IOC Immediate or cancel
+func (b *FutureOrderBuilder) IOC() *FutureOrderBuilder {
+	b.req.TimeInForce = TimeInForceIOC
+	return b
+}
+
+// PostOnly Only add liquidity, the order is canceled if it would take liquidity
+func (b *FutureOrderBuilder) PostOnly() *FutureOrderBuilder {
+	b.req.PostOnly = true
+	return b
+}
+
+// Hidden Hide the order from the order book
+func (b *FutureOrderBuilder) Hidden() *FutureOrderBuilder {
+	b.req.Hidden = true
+	return b
+}
+
+// Iceberg Only show visibleSize contracts of the order in the order book
+func (b *FutureOrderBuilder) Iceberg(visibleSize int) *FutureOrderBuilder {
+	b.req.Iceberg = true
+	b.visibleSize = visibleSize
+	return b
+}
+
+// STP Set the self trade prevention, STPDecrease is not supported by futures
+func (b *FutureOrderBuilder) STP(stp STP) *FutureOrderBuilder {
+	b.req.Stp = stp
+	return b
+}
+
+// Stop Make it a stop order triggered when the price of priceType crosses price in direction, StopDown or StopUp
+func (b *FutureOrderBuilder) Stop(direction StopDirection, price decimal.Decimal, priceType StopPriceType) *FutureOrderBuilder {
+	b.req.Stop = direction
+	b.req.StopPrice = price
+	b.req.StopPriceType = priceType
+	return b
+}
+
+// Build Validate the fields, convert the size to contracts and return the request
+func (b *FutureOrderBuilder) Build() (*FutureOrderRequest, error) {
+	if b.req.Type == "" {
+		return nil, errors.New("order type is not set, call Limit or Market")
+	}
+	if !b.sized {
+		return nil, errors.New("order size is not set")
+	}
+	flags := orderFlags{
+		side:        b.req.Side,
+		orderType:   b.req.Type,
+		price:       b.req.Price,
+		timeInForce: b.req.TimeInForce,
+		postOnly:    b.req.PostOnly,
+		hidden:      b.req.Hidden,
+		iceberg:     b.req.Iceberg,
+		stp:         b.req.Stp,
+	}
+	if err := flags.validate(); err != nil {
+		return nil, err
+	}
+	if b.req.Stp == STPDecrease {
+		return nil, fmt.Errorf("%w: stp %s of a future order", ErrInvalidOrder, b.req.Stp)
+	}
+	if b.req.TimeInForce != "" && b.req.TimeInForce != TimeInForceGTC && b.req.TimeInForce != TimeInForceIOC {
+		return nil, fmt.Errorf("%w: time in force %s of a future order", ErrInvalidOrder, b.req.TimeInForce)
+	}
+	if b.req.Stop != "" {
+		if !b.req.Stop.Valid() {
+			return nil, fmt.Errorf("%w: stop %q", ErrInvalidOrder, b.req.Stop)
+		}
+		if !b.req.StopPrice.IsPositive() || !b.req.StopPriceType.Valid() {
+			return nil, fmt.Errorf("%w: stop price %s of type %q", ErrInvalidOrder, b.req.StopPrice, b.req.StopPriceType)
+		}
+	}
+	if b.contract == nil && (!b.baseQty.IsZero() || !b.notional.IsZero()) {
+		return nil, fmt.Errorf("%w: sizing in base quantity or notional requires the contract", ErrInvalidOrder)
+	}
+	price := b.req.Price
+	if b.req.Type == OrderTypeMarket {
+		price = b.refPrice
+	}
+
+	req := b.req
+	var err error
+	switch {
+	case !b.baseQty.IsZero():
+		req.Size, err = b.contract.ContractsForBase(b.baseQty, price)
+	case !b.notional.IsZero():
+		req.Size, err = b.contract.ContractsForNotional(b.notional, price)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("%w: less than one contract of %s", ErrOrderSizeTooSmall, req.Symbol)
+	}
+	if req.Iceberg {
+		if b.visibleSize <= 0 || b.visibleSize >= req.Size {
+			return nil, fmt.Errorf("%w: visible size %d of size %d", ErrInvalidOrder, b.visibleSize, req.Size)
+		}
+		req.VisibleSize = strconv.Itoa(b.visibleSize)
+	}
+	return &req, nil
+}
@@ -0,0 +1,334 @@
+package kugo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Formats of Exporter
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl" // JSON Lines, one object per line
+)
+
+// Columns of the exported records. They are stable: new columns are only appended
+var (
+	SpotFillColumns = []string{"time", "tradeId", "orderId", "symbol", "side", "liquidity", "type", "tradeType",
+		"price", "size", "funds", "fee", "feeRate", "feeCurrency"}
+	FutureFillColumns = []string{"time", "tradeId", "orderId", "symbol", "side", "liquidity", "orderType", "tradeType",
+		"price", "size", "value", "fee", "feeRate", "fixFee", "feeCurrency", "settleCurrency"}
+	LedgerColumns = []string{"time", "id", "currency", "amount", "fee", "balance", "accountType", "bizType",
+		"direction", "context"}
+)
+
+// exportTimeLayout RFC 3339 with milliseconds
+const exportTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// exportCursorInterval The number of records after which the cursor is saved, besides the end of each history window
+const exportCursorInterval = 1000
+
+// ExportOption Option of Exporter
+type ExportOption func(e *Exporter) error
+
+// SetExportLocation Format the times in the time zone, UTC by default
+func SetExportLocation(loc *time.Location) ExportOption {
+	return func(e *Exporter) error {
+		if e == nil {
+			return errors.New("exporter is nil")
+		}
+		if loc == nil {
+			return errors.New("location is nil")
+		}
+		e.loc = loc
+		return nil
+	}
+}
+
+// SetExportCursor Save the position of the export to the file, so that the next export of the
+// same records continues after the last exported one. Open the output in append mode to resume.
+// The records are flushed and the cursor is saved after each window of the history, every 1000 records
+// and at the end of the export. Records are written at least once: records after the last saved cursor
+// may be written again if the process stops before the cursor is saved
+func SetExportCursor(path string) ExportOption {
+	return func(e *Exporter) error {
+		if e == nil {
+			return errors.New("exporter is nil")
+		}
+		e.cursorPath = path
+		return nil
+	}
+}
+
+// SetExportAppend Set whether the output already holds records, so the CSV header is not written again.
+// By default it is true for an *os.File output which is not empty, and false for other outputs
+func SetExportAppend(appending bool) ExportOption {
+	return func(e *Exporter) error {
+		if e == nil {
+			return errors.New("exporter is nil")
+		}
+		e.header = appending
+		return nil
+	}
+}
+
+// ExportCursor The position of an incremental export
+type ExportCursor struct {
+	Time int64    `json:"time"` // createdAt in milliseconds of the last exported record
+	Ids  []string `json:"ids"`  // Ids of the exported records at Time
+}
+
+// Exporter Write fills or ledger entries to CSV or JSON Lines for accounting.
+// An exporter writes one kind of records with the columns of SpotFillColumns, FutureFillColumns or
+// LedgerColumns. Decimals are written as exact strings and times in RFC 3339 with milliseconds.
+type Exporter struct {
+	w          io.Writer
+	format     string
+	loc        *time.Location
+	cursorPath string
+
+	csv    *csv.Writer
+	kind   string // Kind of the written records
+	header bool   // Whether the CSV header is written
+}
+
+// NewExporter Create an exporter writing to w in the format, ExportCSV or ExportJSONL
+func NewExporter(w io.Writer, format string, opts ...ExportOption) (*Exporter, error) {
+	if format != ExportCSV && format != ExportJSONL {
+		return nil, fmt.Errorf("unknown export format %s", format)
+	}
+	e := &Exporter{w: w, format: format, loc: time.UTC}
+	// The header is in the output already when a file is appended to
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Size() > 0 {
+			e.header = true
+		}
+	}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	if format == ExportCSV {
+		e.csv = csv.NewWriter(w)
+	}
+	return e, nil
+}
+
+// WriteSpotFill Write a fill with SpotFillColumns
+func (e *Exporter) WriteSpotFill(f *SpotOrderFillsItem) error {
+	return e.write("spot fills", SpotFillColumns, []string{
+		e.formatTime(f.CreatedAt), f.TradeId, f.OrderId, f.Symbol, f.Side, f.Liquidity, f.Type, f.TradeType,
+		f.Price.String(), f.Size.String(), f.Funds.String(), f.Fee.String(), f.FeeRate.String(), f.FeeCurrency,
+	})
+}
+
+// WriteFutureFill Write a fill with FutureFillColumns. The size is in contracts
+func (e *Exporter) WriteFutureFill(f *FutureOrderFillsItem) error {
+	return e.write("future fills", FutureFillColumns, []string{
+		e.formatTime(f.CreatedAt), f.TradeId, f.OrderId, f.Symbol, f.Side, f.Liquidity, f.OrderType, f.TradeType,
+		f.Price.String(), strconv.Itoa(f.Size), f.Value.String(), f.Fee.String(), f.FeeRate.String(), f.FixFee.String(),
+		f.FeeCurrency, f.SettleCurrency,
+	})
+}
+
+// WriteLedger Write a ledger entry with LedgerColumns
+func (e *Exporter) WriteLedger(l *LedgerItem) error {
+	return e.write("ledgers", LedgerColumns, []string{
+		e.formatTime(l.CreatedAt), l.Id, l.Currency, l.Amount.String(), l.Fee.String(), l.Balance.String(),
+		l.AccountType, l.BizType, l.Direction, l.Context,
+	})
+}
+
+// Flush Flush the buffered records to the writer
+func (e *Exporter) Flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *Exporter) formatTime(ms int64) string {
+	return time.UnixMilli(ms).In(e.loc).Format(exportTimeLayout)
+}
+
+func (e *Exporter) write(kind string, columns, values []string) error {
+	if e.kind == "" {
+		e.kind = kind
+	} else if e.kind != kind {
+		return fmt.Errorf("the exporter writes %s, not %s", e.kind, kind)
+	}
+
+	if e.format == ExportCSV {
+		if !e.header {
+			if err := e.csv.Write(columns); err != nil {
+				return err
+			}
+			e.header = true
+		}
+		return e.csv.Write(values)
+	}
+
+	// Keys in the order of the columns
+	b := []byte{'{'}
+	for i, column := range columns {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, _ := json.Marshal(column)
+		v, _ := json.Marshal(values[i])
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	b = append(b, '}', '\n')
+	_, err := e.w.Write(b)
+	return err
+}
+
+// loadCursor Return the saved cursor, or nil if there is none
+func (e *Exporter) loadCursor() (*ExportCursor, error) {
+	if e.cursorPath == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(e.cursorPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cursor := &ExportCursor{}
+	if err = json.Unmarshal(b, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor file %s: %v", e.cursorPath, err)
+	}
+	return cursor, nil
+}
+
+// saveCursor Flush the records and save the cursor by replacing the file
+func (e *Exporter) saveCursor(cursor *ExportCursor) error {
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	if e.cursorPath == "" || cursor == nil {
+		return nil
+	}
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp := e.cursorPath + ".tmp"
+	if err = os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.cursorPath)
+}
+
+// exportHistory Write the items of history after the cursor and save the cursor as the export goes.
+// It returns the number of written items
+func exportHistory[T any](e *Exporter, cursor *ExportCursor, history *History[T], key func(item *T) string,
+	createdAt func(item *T) int64, write func(item *T) error) (int, error) {
+	// Skip the records up to the cursor of the last export
+	start := cursor
+	skip := map[string]bool{}
+	if start != nil {
+		for _, id := range start.Ids {
+			skip[id] = true
+		}
+	}
+
+	n, unsaved := 0, 0
+	var err error
+	for history.Next() {
+		item := history.Item()
+		id, t := key(&item), createdAt(&item)
+		if start == nil || t > start.Time || t == start.Time && !skip[id] {
+			if err = write(&item); err != nil {
+				break
+			}
+			n++
+			unsaved++
+			switch {
+			case cursor == nil || t > cursor.Time:
+				cursor = &ExportCursor{Time: t, Ids: []string{id}}
+			case t == cursor.Time:
+				cursor.Ids = append(cursor.Ids, id)
+			}
+		}
+		// Save the progress, so that a stopped process does not write the records again
+		if unsaved > 0 && (history.windowDone() || unsaved >= exportCursorInterval) {
+			if err = e.saveCursor(cursor); err != nil {
+				return n, err
+			}
+			unsaved = 0
+		}
+	}
+	if err == nil {
+		err = history.Err()
+	}
+	// Save the position of the written records even if the export stopped on an error
+	if e2 := e.saveCursor(cursor); err == nil {
+		err = e2
+	}
+	return n, err
+}
+
+// resumeAt Return the start of the export: the time of the cursor if it is after startAt
+func resumeAt(cursor *ExportCursor, startAt int64) int64 {
+	if cursor != nil && cursor.Time > startAt {
+		return cursor.Time
+	}
+	return startAt
+}
+
+// ExportSpotFills Write the fills between req.StartAt and req.EndAt (now if it is 0) with SpotOrderFillsHistory.
+// With SetExportCursor the export starts after the last exported fill. It returns the number of written fills
+func (kc *Kucoin) ExportSpotFills(ctx context.Context, e *Exporter, req *SpotOrderFillsRequest) (int, error) {
+	cursor, err := e.loadCursor()
+	if err != nil {
+		return 0, err
+	}
+	r := *req
+	r.StartAt = resumeAt(cursor, r.StartAt)
+	return exportHistory(e, cursor, kc.SpotOrderFillsHistory(ctx, &r),
+		func(item *SpotOrderFillsItem) string { return item.TradeId },
+		func(item *SpotOrderFillsItem) int64 { return item.CreatedAt },
+		e.WriteSpotFill)
+}
+
+// ExportFutureFills Write the fills between req.StartAt and req.EndAt (now if it is 0) with FutureOrderFillsHistory.
+// With SetExportCursor the export starts after the last exported fill. It returns the number of written fills
+func (kc *Kucoin) ExportFutureFills(ctx context.Context, e *Exporter, req *FutureOrderFillsRequest) (int, error) {
+	cursor, err := e.loadCursor()
+	if err != nil {
+		return 0, err
+	}
+	r := *req
+	r.StartAt = resumeAt(cursor, r.StartAt)
+	return exportHistory(e, cursor, kc.FutureOrderFillsHistory(ctx, &r),
+		func(item *FutureOrderFillsItem) string { return item.TradeId },
+		func(item *FutureOrderFillsItem) int64 { return item.CreatedAt },
+		e.WriteFutureFill)
+}
+
+// ExportLedgers Write the ledger entries between req.StartAt and req.EndAt (now if it is 0) with SpotLedgersHistory.
+// With SetExportCursor the export starts after the last exported entry. It returns the number of written entries
+func (kc *Kucoin) ExportLedgers(ctx context.Context, e *Exporter, req *SpotLedgersRequest) (int, error) {
+	cursor, err := e.loadCursor()
+	if err != nil {
+		return 0, err
+	}
+	r := *req
+	r.StartAt = resumeAt(cursor, r.StartAt)
+	return exportHistory(e, cursor, kc.SpotLedgersHistory(ctx, &r),
+		func(item *LedgerItem) string { return item.Id },
+		func(item *LedgerItem) int64 { return item.CreatedAt },
+		e.WriteLedger)
+}
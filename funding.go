@@ -0,0 +1,247 @@
+package kugo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ErrWithdrawalDisabled is returned by the withdrawal methods unless SetWithdrawal(true) is set.
+var ErrWithdrawalDisabled = errors.New("withdrawal is disabled, use SetWithdrawal(true) to enable it")
+
+// DepositAddressCreate POST /api/v1/deposit-addresses
+func (kc *Kucoin) DepositAddressCreate(req *DepositAddressRequest) (*DepositAddressData, error) {
+	uri := UriDepositAddress
+	p, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodPost, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &DepositAddressResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// DepositAddressOne GET /api/v1/deposit-addresses
+func (kc *Kucoin) DepositAddressOne(currency, chain string) (*DepositAddressData, error) {
+	uri := UriDepositAddress
+	p := map[string]string{}
+	p["currency"] = currency
+	if len(chain) != 0 {
+		p["chain"] = chain
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &DepositAddressResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// DepositAddressList GET /api/v2/deposit-addresses
+// Returns the deposit addresses of all chains of the currency.
+func (kc *Kucoin) DepositAddressList(currency string) ([]DepositAddressData, error) {
+	uri := UriDepositAddressV2
+	p := map[string]string{}
+	p["currency"] = currency
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &DepositAddressListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// DepositAddressListV3 GET /api/v3/deposit-addresses
+// Returns the deposit addresses of the currency, filtered by chain if it is not empty.
+func (kc *Kucoin) DepositAddressListV3(currency, chain string) ([]DepositAddressV3Data, error) {
+	uri := UriDepositAddressV3
+	p := map[string]string{}
+	p["currency"] = currency
+	if len(chain) != 0 {
+		p["chain"] = chain
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &DepositAddressV3Response{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// DepositList GET /api/v1/deposits
+func (kc *Kucoin) DepositList(req *DepositListRequest, currentPage, pageSize int) (*DepositListData, error) {
+	uri := UriDepositList
+	p := map[string]string{}
+	p["currentPage"] = strconv.Itoa(currentPage)
+	p["pageSize"] = strconv.Itoa(pageSize)
+	if len(req.Currency) != 0 {
+		p["currency"] = req.Currency
+	}
+	if len(req.Status) != 0 {
+		p["status"] = req.Status
+	}
+	if req.StartAt != 0 {
+		p["startAt"] = strconv.Itoa(int(req.StartAt))
+	}
+	if req.EndAt != 0 {
+		p["endAt"] = strconv.Itoa(int(req.EndAt))
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &DepositListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// WithdrawalQuotas GET /api/v1/withdrawals/quotas
+func (kc *Kucoin) WithdrawalQuotas(currency, chain string) (*WithdrawalQuotasData, error) {
+	uri := UriWithdrawalQuotas
+	p := map[string]string{}
+	p["currency"] = currency
+	if len(chain) != 0 {
+		p["chain"] = chain
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &WithdrawalQuotasResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// Withdraw POST /api/v1/withdrawals
+// The instance must be created with SetWithdrawal(true).
+func (kc *Kucoin) Withdraw(req *WithdrawRequest) (*WithdrawData, error) {
+	if !kc.withdrawal {
+		return nil, ErrWithdrawalDisabled
+	}
+	uri := UriWithdrawals
+	p, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodPost, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &WithdrawResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// CancelWithdrawal DELETE /api/v1/withdrawals/{withdrawalId}
+// Only withdrawals in PROCESSING status can be cancelled. The instance must be created with SetWithdrawal(true).
+func (kc *Kucoin) CancelWithdrawal(withdrawalId string) error {
+	if !kc.withdrawal {
+		return ErrWithdrawalDisabled
+	}
+	uri := fmt.Sprintf(UriWithdrawalCancel, withdrawalId)
+	resp, err := kc.do(kc.spotEndpoint, http.MethodDelete, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	respStruct := &BaseResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return errors.New(respStruct.Msg)
+	}
+	return nil
+}
+
+// WithdrawalList GET /api/v1/withdrawals
+func (kc *Kucoin) WithdrawalList(req *WithdrawalListRequest, currentPage, pageSize int) (*WithdrawalListData, error) {
+	uri := UriWithdrawals
+	p := map[string]string{}
+	p["currentPage"] = strconv.Itoa(currentPage)
+	p["pageSize"] = strconv.Itoa(pageSize)
+	if len(req.Currency) != 0 {
+		p["currency"] = req.Currency
+	}
+	if len(req.Status) != 0 {
+		p["status"] = req.Status
+	}
+	if req.StartAt != 0 {
+		p["startAt"] = strconv.Itoa(int(req.StartAt))
+	}
+	if req.EndAt != 0 {
+		p["endAt"] = strconv.Itoa(int(req.EndAt))
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &WithdrawalListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
@@ -10,6 +10,17 @@ import (
 
 // FutureOrder POST /api/v1/orders
 func (kc *Kucoin) FutureOrder(req *FutureOrderRequest) (*FutureOrderData, error) {
+	if err := kc.checkFutureStatus(false); err != nil {
+		return nil, err
+	}
+	if kc.normalize {
+		if err := kc.rules.NormalizeFutureOrder(req); err != nil {
+			return nil, err
+		}
+	}
+	if err := kc.fillClientOid(&req.ClientOid); err != nil {
+		return nil, err
+	}
 	uri := UriFutureOrders
 	p, err := json.Marshal(req)
 	if err != nil {
@@ -28,11 +39,15 @@ func (kc *Kucoin) FutureOrder(req *FutureOrderRequest) (*FutureOrderData, error)
 	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
 		return nil, errors.New(respStruct.Msg)
 	}
+	respStruct.Data.ClientOid = req.ClientOid
 	return &respStruct.Data, nil
 }
 
 // FutureOrderCancel DELETE /api/v1/orders/{orderId}
 func (kc *Kucoin) FutureOrderCancel(orderId string) (*FutureOrderCancelData, error) {
+	if err := kc.checkFutureStatus(true); err != nil {
+		return nil, err
+	}
 	uri := fmt.Sprintf(UriFutureOrderCancel, orderId)
 	resp, err := kc.do(kc.futureEndpoint, http.MethodDelete, uri, nil)
 	if err != nil {
@@ -107,6 +122,30 @@ func (kc *Kucoin) FutureOrderOne(orderId string) (*FutureOrderOneData, error) {
 	return &respStruct.Data, nil
 }
 
+// FutureOrderByClientOid GET /api/v1/orders/byClientOid
+// ErrOrderNotFound is returned if there is no order of the clientOid
+func (kc *Kucoin) FutureOrderByClientOid(clientOid string) (*FutureOrderOneData, error) {
+	uri := UriFutureOrderByClient
+	p := map[string]string{"clientOid": clientOid}
+	resp, err := kc.do(kc.futureEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &FutureOrderClientOidResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		// An unknown clientOid is reported with an error
+		return nil, orderNotFoundError(clientOid, respStruct.Msg)
+	}
+	if respStruct.Data == nil {
+		return nil, fmt.Errorf("%w: clientOid %s", ErrOrderNotFound, clientOid)
+	}
+	return respStruct.Data, nil
+}
+
 // FutureOrderFills GET /api/v1/fills
 func (kc *Kucoin) FutureOrderFills(req *FutureOrderFillsRequest, currentPage, pageSize int) (*FutureOrderFillsData, error) {
 	uri := UriFutureOrderFills
@@ -5,6 +5,5 @@ go 1.18
 require (
 	github.com/go-resty/resty/v2 v2.7.0
 	github.com/shopspring/decimal v1.3.1
+	golang.org/x/net v0.0.0-20211029224645-99673261e6eb
 )
-
-require golang.org/x/net v0.0.0-20211029224645-99673261e6eb // indirect
@@ -0,0 +1,204 @@
+package kugo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// The max spans between startAt and endAt of the endpoints
+const (
+	historyWindow = 7 * 24 * time.Hour // Fills and orders
+	ledgersWindow = 24 * time.Hour
+)
+
+// History Iterate the items of a time range longer than the span allowed by an endpoint, 7 days for
+// fills and orders and 1 day for ledgers. The range is split into windows of the span, each window is paged through with a Pager,
+// and its items are deduplicated and sorted by time, so the items are streamed from the oldest.
+// Only one window is held in memory.
+//
+//	history := instance.SpotOrderFillsHistory(ctx, &kugo.SpotOrderFillsRequest{StartAt: start, EndAt: end})
+//	for history.Next() {
+//		fill := history.Item()
+//	}
+//	if err := history.Err(); err != nil {
+//	}
+type History[T any] struct {
+	pages  func(startAt, endAt int64) *Pager[T]
+	key    func(item *T) string
+	timeOf func(item *T) int64
+
+	window int64 // Span of a window in milliseconds
+	next   int64 // Start of the next window in milliseconds
+	end    int64
+	prev   map[string]bool // Keys of the last window, items on the boundary are returned by both windows
+	items  []T
+	i      int
+	item   T
+	err    error
+}
+
+func newHistory[T any](window time.Duration, startAt, endAt int64, pages func(startAt, endAt int64) *Pager[T], key func(item *T) string, timeOf func(item *T) int64) *History[T] {
+	h := &History[T]{pages: pages, key: key, timeOf: timeOf, window: window.Milliseconds(), next: startAt, end: endAt}
+	if startAt <= 0 {
+		h.err = errors.New("startAt is required")
+	} else if endAt < startAt {
+		h.err = errors.New("endAt is before startAt")
+	}
+	return h
+}
+
+// historyRange Return the range of the request in milliseconds, endAt is now if it is not set
+func historyRange(startAt, endAt int64) (int64, int64) {
+	if endAt == 0 {
+		endAt = time.Now().UnixMilli()
+	}
+	return startAt, endAt
+}
+
+// Next Advance to the next item, loading the next window if needed.
+// It returns false when there are no more items or an error occurs, see Err
+func (h *History[T]) Next() bool {
+	for h.i >= len(h.items) {
+		if h.err != nil || h.next >= h.end {
+			return false
+		}
+		h.load()
+	}
+	h.item = h.items[h.i]
+	h.i++
+	return true
+}
+
+// load Page through the next window
+func (h *History[T]) load() {
+	from := h.next
+	to := from + h.window
+	if to > h.end {
+		to = h.end
+	}
+
+	seen := map[string]bool{}
+	var items []T
+	pager := h.pages(from, to)
+	for pager.Next() {
+		item := pager.Item()
+		key := h.key(&item)
+		if seen[key] || h.prev[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+	if h.err = pager.Err(); h.err != nil {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := h.timeOf(&items[i]), h.timeOf(&items[j])
+		if ti != tj {
+			return ti < tj
+		}
+		return h.key(&items[i]) < h.key(&items[j])
+	})
+
+	h.prev = seen
+	h.items, h.i = items, 0
+	h.next = to
+}
+
+// Item Return the current item
+func (h *History[T]) Item() T {
+	return h.item
+}
+
+// windowDone Return whether the current item is the last one of the loaded window
+func (h *History[T]) windowDone() bool {
+	return h.i >= len(h.items)
+}
+
+// Err Return the error which stopped the iteration, nil if it reached the end
+func (h *History[T]) Err() error {
+	return h.err
+}
+
+// SpotOrderFillsHistory Iterate the fills between req.StartAt and req.EndAt (now if it is 0) of any span,
+// deduplicated by tradeId and sorted by time
+func (kc *Kucoin) SpotOrderFillsHistory(ctx context.Context, req *SpotOrderFillsRequest) *History[SpotOrderFillsItem] {
+	startAt, endAt := historyRange(req.StartAt, req.EndAt)
+	return newHistory(historyWindow, startAt, endAt,
+		func(startAt, endAt int64) *Pager[SpotOrderFillsItem] {
+			r := *req
+			r.StartAt, r.EndAt = startAt, endAt
+			return kc.SpotOrderFillsAll(ctx, &r)
+		},
+		func(item *SpotOrderFillsItem) string { return item.TradeId },
+		func(item *SpotOrderFillsItem) int64 { return item.CreatedAt },
+	)
+}
+
+// SpotOrderListHistory Iterate the orders between req.StartAt and req.EndAt (now if it is 0) of any span,
+// deduplicated by orderId and sorted by creation time
+func (kc *Kucoin) SpotOrderListHistory(ctx context.Context, req *SpotOrderListRequest) *History[SpotOrderOneData] {
+	startAt, endAt := historyRange(req.StartAt, req.EndAt)
+	return newHistory(historyWindow, startAt, endAt,
+		func(startAt, endAt int64) *Pager[SpotOrderOneData] {
+			r := *req
+			r.StartAt, r.EndAt = startAt, endAt
+			return kc.SpotOrderListAll(ctx, &r)
+		},
+		func(item *SpotOrderOneData) string { return item.Id },
+		func(item *SpotOrderOneData) int64 { return item.CreatedAt },
+	)
+}
+
+// FutureOrderFillsHistory Iterate the fills between req.StartAt and req.EndAt (now if it is 0) of any span,
+// deduplicated by tradeId and sorted by trade time
+func (kc *Kucoin) FutureOrderFillsHistory(ctx context.Context, req *FutureOrderFillsRequest) *History[FutureOrderFillsItem] {
+	startAt, endAt := historyRange(req.StartAt, req.EndAt)
+	return newHistory(historyWindow, startAt, endAt,
+		func(startAt, endAt int64) *Pager[FutureOrderFillsItem] {
+			r := *req
+			r.StartAt, r.EndAt = startAt, endAt
+			return kc.FutureOrderFillsAll(ctx, &r)
+		},
+		func(item *FutureOrderFillsItem) string { return item.TradeId },
+		func(item *FutureOrderFillsItem) int64 {
+			// tradeTime is in nanoseconds
+			if item.TradeTime != 0 {
+				return item.TradeTime
+			}
+			return item.CreatedAt * int64(time.Millisecond)
+		},
+	)
+}
+
+// FutureOrderListHistory Iterate the orders between req.StartAt and req.EndAt (now if it is 0) of any span,
+// deduplicated by orderId and sorted by creation time
+func (kc *Kucoin) FutureOrderListHistory(ctx context.Context, req *FutureOrderListRequest) *History[FutureOrderOneData] {
+	startAt, endAt := historyRange(req.StartAt, req.EndAt)
+	return newHistory(historyWindow, startAt, endAt,
+		func(startAt, endAt int64) *Pager[FutureOrderOneData] {
+			r := *req
+			r.StartAt, r.EndAt = startAt, endAt
+			return kc.FutureOrderListAll(ctx, &r)
+		},
+		func(item *FutureOrderOneData) string { return item.Id },
+		func(item *FutureOrderOneData) int64 { return item.CreatedAt },
+	)
+}
+
+// SpotLedgersHistory Iterate the ledger entries between req.StartAt and req.EndAt (now if it is 0) of any span,
+// deduplicated by id and sorted by time
+func (kc *Kucoin) SpotLedgersHistory(ctx context.Context, req *SpotLedgersRequest) *History[LedgerItem] {
+	startAt, endAt := historyRange(req.StartAt, req.EndAt)
+	return newHistory(ledgersWindow, startAt, endAt,
+		func(startAt, endAt int64) *Pager[LedgerItem] {
+			r := *req
+			r.StartAt, r.EndAt = startAt, endAt
+			return kc.SpotLedgersAll(ctx, &r)
+		},
+		func(item *LedgerItem) string { return item.Id },
+		func(item *LedgerItem) int64 { return item.CreatedAt },
+	)
+}
@@ -0,0 +1,299 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Types of instrument events
+const (
+	InstrumentListed   = "listed"   // A new instrument is listed
+	InstrumentDelisted = "delisted" // The instrument is no longer listed
+	InstrumentDisabled = "disabled" // Trading of the instrument is disabled
+	InstrumentEnabled  = "enabled"  // Trading of the instrument is enabled again
+)
+
+// Markets of instruments
+const (
+	InstrumentSpot   = "spot"
+	InstrumentFuture = "future"
+)
+
+// InstrumentEvent A change of an instrument found by refreshing the Instruments
+type InstrumentEvent struct {
+	Type   string
+	Market string // spot or future
+	Symbol string
+	Spot   *SymbolsData      // Only for spot instruments. The last known data for delisted ones
+	Future *FutureSymbolData // Only for future instruments. The last known data for delisted ones
+}
+
+// InstrumentsOption Option of Instruments
+type InstrumentsOption func(in *Instruments) error
+
+// SetInstrumentsInterval Set the refresh interval, 10 minutes by default
+func SetInstrumentsInterval(interval time.Duration) InstrumentsOption {
+	return func(in *Instruments) error {
+		if in == nil {
+			return errors.New("instruments is nil")
+		}
+		if interval <= 0 {
+			return errors.New("interval must be positive")
+		}
+		in.interval = interval
+		return nil
+	}
+}
+
+// SetInstrumentsHandler Set the handler of the instrument events.
+// Events are found by comparing a refresh with the previous one, so the first load emits none
+func SetInstrumentsHandler(handler func(e *InstrumentEvent)) InstrumentsOption {
+	return func(in *Instruments) error {
+		if in == nil {
+			return errors.New("instruments is nil")
+		}
+		in.handler = handler
+		return nil
+	}
+}
+
+// Instruments A registry of the spot symbols and future contracts, refreshed in the background
+// with SpotSymbols and FutureSymbols. It is safe for concurrent use.
+type Instruments struct {
+	kc       *Kucoin
+	interval time.Duration
+	handler  func(e *InstrumentEvent)
+
+	mu       sync.RWMutex
+	spot     map[string]SymbolsData
+	future   map[string]FutureSymbolData
+	loaded   bool
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewInstruments Create a registry of the instruments. Call Start to load and refresh them
+func NewInstruments(kc *Kucoin, opts ...InstrumentsOption) (*Instruments, error) {
+	in := &Instruments{
+		kc:       kc,
+		interval: 10 * time.Minute,
+		spot:     map[string]SymbolsData{},
+		future:   map[string]FutureSymbolData{},
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(in); err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+// Start Load the instruments and refresh them every interval until Close is called
+func (in *Instruments) Start() error {
+	if err := in.Refresh(); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(in.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-in.stop:
+				return
+			case <-ticker.C:
+			}
+			if err := in.Refresh(); err != nil && in.kc.debug {
+				in.kc.respLog(fmt.Sprintf("info:instruments_refresh\terror:%v", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Close Stop refreshing
+func (in *Instruments) Close() {
+	in.stopOnce.Do(func() {
+		close(in.stop)
+	})
+}
+
+// Refresh Load the instruments now and emit the events of the changes.
+// A market which fails to load keeps its previous instruments
+func (in *Instruments) Refresh() error {
+	spot, spotErr := in.kc.SpotSymbols("")
+	future, futureErr := in.kc.FutureSymbols()
+
+	in.mu.Lock()
+	var events []*InstrumentEvent
+	if spotErr == nil {
+		events = append(events, in.updateSpot(spot)...)
+	}
+	if futureErr == nil {
+		events = append(events, in.updateFuture(future)...)
+	}
+	if spotErr == nil && futureErr == nil {
+		in.loaded = true
+	}
+	handler := in.handler
+	in.mu.Unlock()
+
+	if handler != nil {
+		for _, e := range events {
+			handler(e)
+		}
+	}
+	if spotErr != nil {
+		return spotErr
+	}
+	return futureErr
+}
+
+// updateSpot in.mu must be held
+func (in *Instruments) updateSpot(symbols []SymbolsData) []*InstrumentEvent {
+	var events []*InstrumentEvent
+	next := make(map[string]SymbolsData, len(symbols))
+	for i := range symbols {
+		s := symbols[i]
+		next[s.Symbol] = s
+		if !in.loaded {
+			continue
+		}
+		prev, ok := in.spot[s.Symbol]
+		switch {
+		case !ok:
+			events = append(events, &InstrumentEvent{Type: InstrumentListed, Market: InstrumentSpot, Symbol: s.Symbol, Spot: &s})
+		case prev.EnableTrading && !s.EnableTrading:
+			events = append(events, &InstrumentEvent{Type: InstrumentDisabled, Market: InstrumentSpot, Symbol: s.Symbol, Spot: &s})
+		case !prev.EnableTrading && s.EnableTrading:
+			events = append(events, &InstrumentEvent{Type: InstrumentEnabled, Market: InstrumentSpot, Symbol: s.Symbol, Spot: &s})
+		}
+	}
+	if in.loaded {
+		for symbol := range in.spot {
+			if _, ok := next[symbol]; !ok {
+				s := in.spot[symbol]
+				events = append(events, &InstrumentEvent{Type: InstrumentDelisted, Market: InstrumentSpot, Symbol: symbol, Spot: &s})
+			}
+		}
+	}
+	in.spot = next
+	return events
+}
+
+// updateFuture in.mu must be held
+func (in *Instruments) updateFuture(symbols []FutureSymbolData) []*InstrumentEvent {
+	var events []*InstrumentEvent
+	next := make(map[string]FutureSymbolData, len(symbols))
+	for i := range symbols {
+		s := symbols[i]
+		next[s.Symbol] = s
+		if !in.loaded {
+			continue
+		}
+		prev, ok := in.future[s.Symbol]
+		switch {
+		case !ok:
+			events = append(events, &InstrumentEvent{Type: InstrumentListed, Market: InstrumentFuture, Symbol: s.Symbol, Future: &s})
+		case futureTradable(&prev) && !futureTradable(&s):
+			events = append(events, &InstrumentEvent{Type: InstrumentDisabled, Market: InstrumentFuture, Symbol: s.Symbol, Future: &s})
+		case !futureTradable(&prev) && futureTradable(&s):
+			events = append(events, &InstrumentEvent{Type: InstrumentEnabled, Market: InstrumentFuture, Symbol: s.Symbol, Future: &s})
+		}
+	}
+	if in.loaded {
+		for symbol := range in.future {
+			if _, ok := next[symbol]; !ok {
+				s := in.future[symbol]
+				events = append(events, &InstrumentEvent{Type: InstrumentDelisted, Market: InstrumentFuture, Symbol: symbol, Future: &s})
+			}
+		}
+	}
+	in.future = next
+	return events
+}
+
+// futureTradable Contracts are tradable in Open status
+func futureTradable(s *FutureSymbolData) bool {
+	return s.Status == "Open"
+}
+
+// Spot Return the spot symbol
+func (in *Instruments) Spot(symbol string) (SymbolsData, bool) {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	s, ok := in.spot[symbol]
+	return s, ok
+}
+
+// Future Return the future contract
+func (in *Instruments) Future(symbol string) (FutureSymbolData, bool) {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	s, ok := in.future[symbol]
+	return s, ok
+}
+
+// Spots Return the spot symbols sorted by symbol
+func (in *Instruments) Spots() []SymbolsData {
+	return in.spotWhere(func(s *SymbolsData) bool { return true })
+}
+
+// SpotByBase Return the spot symbols of the base currency
+func (in *Instruments) SpotByBase(currency string) []SymbolsData {
+	return in.spotWhere(func(s *SymbolsData) bool { return s.BaseCurrency == currency })
+}
+
+// SpotByQuote Return the spot symbols of the quote currency
+func (in *Instruments) SpotByQuote(currency string) []SymbolsData {
+	return in.spotWhere(func(s *SymbolsData) bool { return s.QuoteCurrency == currency })
+}
+
+// Futures Return the future contracts sorted by symbol
+func (in *Instruments) Futures() []FutureSymbolData {
+	return in.futureWhere(func(s *FutureSymbolData) bool { return true })
+}
+
+// FutureByBase Return the future contracts of the base currency
+func (in *Instruments) FutureByBase(currency string) []FutureSymbolData {
+	return in.futureWhere(func(s *FutureSymbolData) bool { return s.BaseCurrency == currency })
+}
+
+// FutureByQuote Return the future contracts of the quote currency
+func (in *Instruments) FutureByQuote(currency string) []FutureSymbolData {
+	return in.futureWhere(func(s *FutureSymbolData) bool { return s.QuoteCurrency == currency })
+}
+
+// FutureBySettle Return the future contracts settled in the currency
+func (in *Instruments) FutureBySettle(currency string) []FutureSymbolData {
+	return in.futureWhere(func(s *FutureSymbolData) bool { return s.SettleCurrency == currency })
+}
+
+func (in *Instruments) spotWhere(cond func(s *SymbolsData) bool) []SymbolsData {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	var result []SymbolsData
+	for _, s := range in.spot {
+		if cond(&s) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+func (in *Instruments) futureWhere(cond func(s *FutureSymbolData) bool) []FutureSymbolData {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	var result []FutureSymbolData
+	for _, s := range in.future {
+		if cond(&s) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
@@ -8,7 +8,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,10 +25,25 @@ type Kucoin struct {
 	passphrase     string
 	signer         Signer
 
-	client  *resty.Client
-	reqLog  func(...interface{})
-	respLog func(...interface{})
-	debug   bool
+	client     *resty.Client
+	reqLog     func(...interface{})
+	respLog    func(...interface{})
+	debug      bool
+	withdrawal bool
+	normalize  bool
+	rules      *SymbolRules
+
+	spotLimiter   *rateLimiter
+	futureLimiter *rateLimiter
+
+	clientOidPrefix string
+	clientOidGen    func() string
+
+	statusInterval time.Duration
+	statusMu       sync.RWMutex
+	statusStop     chan struct{}
+	spotStatus     string
+	futureStatus   string
 }
 
 type Option func(kc *Kucoin) error
@@ -50,7 +65,9 @@ func NewKucoin(opts ...Option) (*Kucoin, error) {
 		return nil, err
 	}
 
+	kc.rules = NewSymbolRules(kc, symbolRulesTTL)
 	kc.registerMiddleware()
+	kc.watchServiceStatus()
 	return kc, nil
 }
 
@@ -155,6 +172,18 @@ func SetDebug(debug bool) Option {
 	}
 }
 
+// SetWithdrawal Allow the instance to move funds out of the account.
+// Withdraw and CancelWithdrawal return ErrWithdrawalDisabled unless it is set to true
+func SetWithdrawal(enable bool) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		kc.withdrawal = enable
+		return nil
+	}
+}
+
 // Register HTTP request middleware
 func (kc *Kucoin) registerMiddleware() {
 	// Registering Request Middleware
@@ -194,6 +223,9 @@ func (kc *Kucoin) registerMiddleware() {
 // do("https://www.kucoin.com", "GET", "/api/v1/accounts", map[string]string{"currency":"BTC", "type":"trade"})
 // do("https://www.kucoin.com", "POST", "/api/v1/orders", []byte("{\"price\":\"100\",...}"))
 func (kc *Kucoin) do(endpoint string, method string, uri string, params interface{}) (resp *resty.Response, err error) {
+	if limiter := kc.limiter(endpoint); limiter != nil {
+		limiter.wait()
+	}
 	us := fmt.Sprintf("%s%s", endpoint, uri)
 	header := make(map[string]string)
 	body := make([]byte, 0)
@@ -203,11 +235,11 @@ func (kc *Kucoin) do(endpoint string, method string, uri string, params interfac
 		if method == http.MethodGet || method == http.MethodDelete {
 			if params != nil {
 				p, _ := params.(map[string]string)
-				qs := make([]string, 0, len(p))
+				qs := url.Values{}
 				for k, v := range p {
-					qs = append(qs, fmt.Sprintf("%s=%s", k, v))
+					qs.Set(k, v)
 				}
-				us = fmt.Sprintf("%s?%s", us, strings.Join(qs, "&"))
+				us = fmt.Sprintf("%s?%s", us, qs.Encode())
 			}
 			u, e := url.Parse(us)
 			if e != nil {
@@ -245,7 +277,6 @@ func (kc *Kucoin) do(endpoint string, method string, uri string, params interfac
 		return
 	default:
 		err = errors.New("method error")
-		return
 	}
 	return
 }
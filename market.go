@@ -3,7 +3,10 @@ package kugo
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
 	"net/http"
+	"strings"
 )
 
 // SpotSymbols GET /api/v2/symbols
@@ -47,3 +50,295 @@ func (kc *Kucoin) FutureSymbols() ([]FutureSymbolData, error) {
 	}
 	return respStruct.Data, nil
 }
+
+// SpotTicker GET /api/v1/market/orderbook/level1
+func (kc *Kucoin) SpotTicker(symbol string) (*SpotTickerData, error) {
+	uri := UriSpotTicker
+	p := map[string]string{}
+	p["symbol"] = symbol
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotTickerResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotAllTickers GET /api/v1/market/allTickers
+func (kc *Kucoin) SpotAllTickers() (*SpotAllTickersData, error) {
+	uri := UriSpotAllTickers
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotAllTickersResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotCurrencies GET /api/v3/currencies
+func (kc *Kucoin) SpotCurrencies() ([]CurrencyData, error) {
+	uri := UriSpotCurrencies
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &CurrenciesResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// SpotCurrency GET /api/v3/currencies/{currency}
+// If chain is empty, all chains of the currency are returned.
+func (kc *Kucoin) SpotCurrency(currency, chain string) (*CurrencyData, error) {
+	uri := fmt.Sprintf(UriSpotCurrency, currency)
+	p := map[string]string{}
+	if len(chain) != 0 {
+		p["chain"] = chain
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &CurrencyResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotPrices GET /api/v1/prices
+// Returns the fiat prices of the currencies, keyed by currency. base is the fiat currency, USD as default.
+// All currencies are returned if currencies is empty.
+func (kc *Kucoin) SpotPrices(base string, currencies ...string) (map[string]decimal.Decimal, error) {
+	uri := UriSpotPrices
+	p := map[string]string{}
+	if len(base) != 0 {
+		p["base"] = base
+	}
+	if len(currencies) != 0 {
+		p["currencies"] = strings.Join(currencies, ",")
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &PricesResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// SpotServiceStatus GET /api/v1/status
+func (kc *Kucoin) SpotServiceStatus() (*ServiceStatusData, error) {
+	uri := UriSpotStatus
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &ServiceStatusResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// FutureServiceStatus GET /api/v1/status
+func (kc *Kucoin) FutureServiceStatus() (*ServiceStatusData, error) {
+	uri := UriFutureStatus
+
+	resp, err := kc.do(kc.futureEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &ServiceStatusResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotOrderBook GET /api/v3/market/orderbook/level2
+// Returns the full level2 order book. The API key is required.
+func (kc *Kucoin) SpotOrderBook(symbol string) (*SpotOrderBookData, error) {
+	return kc.spotOrderBook(UriSpotOrderBook, symbol)
+}
+
+// SpotOrderBookPart GET /api/v1/market/orderbook/level2_{depth}
+// Returns the best 20 or 100 bids and asks.
+func (kc *Kucoin) SpotOrderBookPart(symbol string, depth int) (*SpotOrderBookData, error) {
+	if depth != 20 && depth != 100 {
+		return nil, errors.New("depth must be 20 or 100")
+	}
+	return kc.spotOrderBook(fmt.Sprintf(UriSpotOrderBookPart, depth), symbol)
+}
+
+func (kc *Kucoin) spotOrderBook(uri, symbol string) (*SpotOrderBookData, error) {
+	p := map[string]string{}
+	p["symbol"] = symbol
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotOrderBookResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// FutureOrderBook GET /api/v1/level2/snapshot
+func (kc *Kucoin) FutureOrderBook(symbol string) (*FutureOrderBookData, error) {
+	uri := UriFutureOrderBook
+	p := map[string]string{}
+	p["symbol"] = symbol
+
+	resp, err := kc.do(kc.futureEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &FutureOrderBookResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotOrderBookLevel3 GET /api/v3/market/orderbook/level3
+// Returns the full order book by order. The API key is required.
+func (kc *Kucoin) SpotOrderBookLevel3(symbol string) (*SpotOrderBookLevel3Data, error) {
+	uri := UriSpotOrderBookLevel3
+	p := map[string]string{}
+	p["symbol"] = symbol
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotOrderBookLevel3Response{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	for i := range respStruct.Data.Bids {
+		respStruct.Data.Bids[i].Side = "buy"
+	}
+	for i := range respStruct.Data.Asks {
+		respStruct.Data.Asks[i].Side = "sell"
+	}
+	return &respStruct.Data, nil
+}
+
+// SpotKlines GET /api/v1/market/candles
+// candleType is one of 1min, 3min, 5min, 15min, 30min, 1hour, 2hour, 4hour, 6hour, 8hour, 12hour, 1day, 1week.
+// startAt and endAt are in seconds, 0 for no limit. At most 1500 candles are returned, the newest first.
+func (kc *Kucoin) SpotKlines(symbol, candleType string, startAt, endAt int64) ([]Candle, error) {
+	uri := UriSpotKlines
+	p := map[string]string{}
+	p["symbol"] = symbol
+	p["type"] = candleType
+	if startAt > 0 {
+		p["startAt"] = fmt.Sprint(startAt)
+	}
+	if endAt > 0 {
+		p["endAt"] = fmt.Sprint(endAt)
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotKlinesResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// FutureKlines GET /api/v1/kline/query
+// granularity is in minutes, one of 1, 5, 15, 30, 60, 120, 240, 480, 720, 1440, 10080.
+// from and to are in milliseconds, 0 for no limit. At most 200 candles are returned, the oldest first.
+func (kc *Kucoin) FutureKlines(symbol string, granularity int, from, to int64) ([]FutureCandle, error) {
+	uri := UriFutureKlines
+	p := map[string]string{}
+	p["symbol"] = symbol
+	p["granularity"] = fmt.Sprint(granularity)
+	if from > 0 {
+		p["from"] = fmt.Sprint(from)
+	}
+	if to > 0 {
+		p["to"] = fmt.Sprint(to)
+	}
+
+	resp, err := kc.do(kc.futureEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &FutureKlinesResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
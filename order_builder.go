@@ -0,0 +1,322 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOrder is returned by the order builders when the fields of the order are missing or exclude each other
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Valid Return whether it is a known side
+func (s Side) Valid() bool {
+	return s == SideBuy || s == SideSell
+}
+
+// Valid Return whether it is a known order type
+func (t OrderType) Valid() bool {
+	return t == OrderTypeLimit || t == OrderTypeMarket
+}
+
+// Valid Return whether it is a known time in force
+func (t TimeInForce) Valid() bool {
+	switch t {
+	case TimeInForceGTC, TimeInForceGTT, TimeInForceIOC, TimeInForceFOK:
+		return true
+	}
+	return false
+}
+
+// Valid Return whether it is a known self trade prevention
+func (s STP) Valid() bool {
+	switch s {
+	case STPCancelNewest, STPCancelOldest, STPCancelBoth, STPDecrease:
+		return true
+	}
+	return false
+}
+
+// Valid Return whether it is a known trade type
+func (t TradeType) Valid() bool {
+	switch t {
+	case TradeTypeSpot, TradeTypeMargin, TradeTypeIsolatedMargin:
+		return true
+	}
+	return false
+}
+
+// Valid Return whether it is a known margin mode
+func (m MarginMode) Valid() bool {
+	return m == MarginModeCross || m == MarginModeIsolated
+}
+
+// Valid Return whether it is a known stop price type
+func (t StopPriceType) Valid() bool {
+	switch t {
+	case StopPriceTypeTrade, StopPriceTypeIndex, StopPriceTypeMark:
+		return true
+	}
+	return false
+}
+
+// Valid Return whether it is a known stop direction
+func (d StopDirection) Valid() bool {
+	return d == StopDown || d == StopUp
+}
+
+// orderFlags The fields shared by spot and future orders which exclude each other
+type orderFlags struct {
+	side        Side
+	orderType   OrderType
+	price       decimal.Decimal
+	timeInForce TimeInForce
+	cancelAfter int64
+	postOnly    bool
+	hidden      bool
+	iceberg     bool
+	stp         STP
+}
+
+func (f *orderFlags) validate() error {
+	if !f.side.Valid() {
+		return fmt.Errorf("%w: side %q", ErrInvalidOrder, f.side)
+	}
+	if !f.orderType.Valid() {
+		return fmt.Errorf("%w: type %q", ErrInvalidOrder, f.orderType)
+	}
+	if f.stp != "" && !f.stp.Valid() {
+		return fmt.Errorf("%w: stp %q", ErrInvalidOrder, f.stp)
+	}
+	if f.orderType == OrderTypeMarket {
+		switch {
+		case !f.price.IsZero():
+			return fmt.Errorf("%w: price of a market order", ErrInvalidOrder)
+		case f.timeInForce != "" || f.cancelAfter != 0:
+			return fmt.Errorf("%w: time in force of a market order", ErrInvalidOrder)
+		case f.postOnly || f.hidden || f.iceberg:
+			return fmt.Errorf("%w: post only, hidden or iceberg market order", ErrInvalidOrder)
+		}
+		return nil
+	}
+
+	if !f.price.IsPositive() {
+		return fmt.Errorf("%w: price %s", ErrInvalidOrder, f.price)
+	}
+	if f.timeInForce != "" && !f.timeInForce.Valid() {
+		return fmt.Errorf("%w: time in force %q", ErrInvalidOrder, f.timeInForce)
+	}
+	if f.timeInForce == TimeInForceGTT && f.cancelAfter <= 0 {
+		return fmt.Errorf("%w: GTT requires a positive cancel after", ErrInvalidOrder)
+	}
+	if f.timeInForce != TimeInForceGTT && f.cancelAfter != 0 {
+		return fmt.Errorf("%w: cancel after is only allowed with GTT", ErrInvalidOrder)
+	}
+	if f.postOnly && (f.timeInForce == TimeInForceIOC || f.timeInForce == TimeInForceFOK) {
+		return fmt.Errorf("%w: post only with %s", ErrInvalidOrder, f.timeInForce)
+	}
+	if f.hidden && f.iceberg {
+		return fmt.Errorf("%w: hidden and iceberg", ErrInvalidOrder)
+	}
+	return nil
+}
+
+// SpotOrderBuilder Build a SpotOrdersRequest or a SpotMarginOrderRequest.
+// Build validates the fields which exclude each other: size and funds, hidden and iceberg,
+// post only with IOC or FOK, and the fields which are only allowed for limit orders.
+type SpotOrderBuilder struct {
+	flags       orderFlags
+	req         SpotOrdersRequest
+	funds       decimal.Decimal
+	visibleSize decimal.Decimal
+}
+
+// NewSpotLimit Create a builder of a limit order of size at price
+func NewSpotLimit(symbol string, side Side, price, size decimal.Decimal) *SpotOrderBuilder {
+	return &SpotOrderBuilder{
+		flags: orderFlags{side: side, orderType: OrderTypeLimit, price: price},
+		req:   SpotOrdersRequest{Symbol: symbol, Size: size},
+	}
+}
+
+// NewSpotMarket Create a builder of a market order of size in the base currency
+func NewSpotMarket(symbol string, side Side, size decimal.Decimal) *SpotOrderBuilder {
+	return &SpotOrderBuilder{
+		flags: orderFlags{side: side, orderType: OrderTypeMarket},
+		req:   SpotOrdersRequest{Symbol: symbol, Size: size},
+	}
+}
+
+// NewSpotMarketFunds Create a builder of a market order of funds in the quote currency
+func NewSpotMarketFunds(symbol string, side Side, funds decimal.Decimal) *SpotOrderBuilder {
+	return &SpotOrderBuilder{
+		flags: orderFlags{side: side, orderType: OrderTypeMarket},
+		req:   SpotOrdersRequest{Symbol: symbol},
+		funds: funds,
+	}
+}
+
+// ClientOid Set the client order id
+func (b *SpotOrderBuilder) ClientOid(clientOid string) *SpotOrderBuilder {
+	b.req.ClientOid = clientOid
+	return b
+}
+
+// Remark Set the remark of the order
+func (b *SpotOrderBuilder) Remark(remark string) *SpotOrderBuilder {
+	b.req.Remark = remark
+	return b
+}
+
+// STP Set the self trade prevention
+func (b *SpotOrderBuilder) STP(stp STP) *SpotOrderBuilder {
+	b.flags.stp = stp
+	return b
+}
+
+// TradeType Set the trade type. Not used by margin orders
+func (b *SpotOrderBuilder) TradeType(tradeType TradeType) *SpotOrderBuilder {
+	b.req.TradeType = tradeType
+	return b
+}
+
+// Size Set the size in the base currency
+func (b *SpotOrderBuilder) Size(size decimal.Decimal) *SpotOrderBuilder {
+	b.req.Size = size
+	return b
+}
+
+// Funds Set the funds in the quote currency. Only for market orders
+func (b *SpotOrderBuilder) Funds(funds decimal.Decimal) *SpotOrderBuilder {
+	b.funds = funds
+	return b
+}
+
+// GTC Good till canceled
+func (b *SpotOrderBuilder) GTC() *SpotOrderBuilder {
+	b.flags.timeInForce, b.flags.cancelAfter = TimeInForceGTC, 0
+	return b
+}
+
+// GTT Good till time, canceled after seconds
+func (b *SpotOrderBuilder) GTT(seconds int64) *SpotOrderBuilder {
+	b.flags.timeInForce, b.flags.cancelAfter = TimeInForceGTT, seconds
+	return b
+}
+
+// IOC Immediate or cancel
+func (b *SpotOrderBuilder) IOC() *SpotOrderBuilder {
+	b.flags.timeInForce, b.flags.cancelAfter = TimeInForceIOC, 0
+	return b
+}
+
+// FOK Fill or kill
+func (b *SpotOrderBuilder) FOK() *SpotOrderBuilder {
+	b.flags.timeInForce, b.flags.cancelAfter = TimeInForceFOK, 0
+	return b
+}
+
+// PostOnly Only add liquidity, the order is canceled if it would take liquidity
+func (b *SpotOrderBuilder) PostOnly() *SpotOrderBuilder {
+	b.flags.postOnly = true
+	return b
+}
+
+// Hidden Hide the order from the order book
+func (b *SpotOrderBuilder) Hidden() *SpotOrderBuilder {
+	b.flags.hidden = true
+	return b
+}
+
+// Iceberg Only show visibleSize of the order in the order book
+func (b *SpotOrderBuilder) Iceberg(visibleSize decimal.Decimal) *SpotOrderBuilder {
+	b.flags.iceberg = true
+	b.visibleSize = visibleSize
+	return b
+}
+
+// Build Validate the fields and return the request of SpotOrder
+func (b *SpotOrderBuilder) Build() (*SpotOrdersRequest, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	if b.req.TradeType != "" && !b.req.TradeType.Valid() {
+		return nil, fmt.Errorf("%w: trade type %q", ErrInvalidOrder, b.req.TradeType)
+	}
+	req := b.req
+	req.Side = b.flags.side
+	req.Type = b.flags.orderType
+	req.Price = b.flags.price
+	req.TimeInForce = b.flags.timeInForce
+	req.CancelAfter = b.flags.cancelAfter
+	req.PostOnly = b.flags.postOnly
+	req.Hidden = b.flags.hidden
+	req.Iceberg = b.flags.iceberg
+	req.Stp = b.flags.stp
+	if b.flags.iceberg {
+		req.VisibleSize = b.visibleSize.String()
+	}
+	if !b.funds.IsZero() {
+		req.Funds = b.funds.String()
+	}
+	return &req, nil
+}
+
+// BuildMargin Validate the fields and return the request of SpotMarginOrder
+func (b *SpotOrderBuilder) BuildMargin(mode MarginMode, autoBorrow bool) (*SpotMarginOrderRequest, error) {
+	if !mode.Valid() {
+		return nil, fmt.Errorf("%w: margin mode %q", ErrInvalidOrder, mode)
+	}
+	if b.req.TradeType != "" {
+		return nil, fmt.Errorf("%w: trade type of a margin order", ErrInvalidOrder)
+	}
+	req, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &SpotMarginOrderRequest{
+		ClientOid:   req.ClientOid,
+		Side:        req.Side,
+		Symbol:      req.Symbol,
+		Type:        req.Type,
+		Remark:      req.Remark,
+		Stp:         req.Stp,
+		MarginModel: mode,
+		AutoBorrow:  autoBorrow,
+		Price:       req.Price,
+		Size:        req.Size,
+		TimeInForce: req.TimeInForce,
+		CancelAfter: req.CancelAfter,
+		PostOnly:    req.PostOnly,
+		Hidden:      req.Hidden,
+		Iceberg:     req.Iceberg,
+		VisibleSize: req.VisibleSize,
+		Funds:       req.Funds,
+	}, nil
+}
+
+func (b *SpotOrderBuilder) validate() error {
+	if err := b.flags.validate(); err != nil {
+		return err
+	}
+	size, funds := b.req.Size, b.funds
+	if size.IsNegative() || funds.IsNegative() {
+		return fmt.Errorf("%w: size %s, funds %s", ErrInvalidOrder, size, funds)
+	}
+	if b.flags.orderType == OrderTypeMarket {
+		if size.IsZero() == funds.IsZero() {
+			return fmt.Errorf("%w: a market order requires one of size and funds", ErrInvalidOrder)
+		}
+		return nil
+	}
+	if !funds.IsZero() {
+		return fmt.Errorf("%w: funds of a limit order", ErrInvalidOrder)
+	}
+	if size.IsZero() {
+		return fmt.Errorf("%w: a limit order requires size", ErrInvalidOrder)
+	}
+	if b.flags.iceberg && (!b.visibleSize.IsPositive() || !b.visibleSize.LessThan(size)) {
+		return fmt.Errorf("%w: visible size %s of size %s", ErrInvalidOrder, b.visibleSize, size)
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOrderBookNotSynced is returned when reading a LocalOrderBook which is not synchronized yet
+var ErrOrderBookNotSynced = errors.New("order book is not synchronized")
+
+// ErrOrderBookDepth is returned by VWAP when the order book is not deep enough for the size
+var ErrOrderBookDepth = errors.New("order book is not deep enough")
+
+// orderBookMaxBuffer The maximum number of messages buffered by an order book while synchronizing
+const orderBookMaxBuffer = 10000
+
+// errOrderBookStale is returned by sync when the snapshot is older than the buffered deltas
+var errOrderBookStale = errors.New("snapshot is older than the level2 stream")
+
+// LocalOrderBook A level2 order book maintained from the level2 stream.
+// Deltas are buffered while the REST snapshot is fetched, then applied by sequence.
+// When a gap in the sequence is detected, the book is synchronized again with a new snapshot.
+// It is safe for concurrent use.
+type LocalOrderBook struct {
+	symbol    string
+	topic     string
+	ws        *WsClient
+	subscribe func() error
+	snapshot  func() (*orderBookSnapshot, error)
+	retryWait time.Duration
+
+	mu       sync.RWMutex
+	bids     []OrderBookLevel // Sorted by price descending
+	asks     []OrderBookLevel // Sorted by price ascending
+	sequence int64
+	synced   bool
+	syncing  bool
+	buffer   []*orderBookDelta
+	changes  chan struct{}
+	closed   chan struct{}
+}
+
+type orderBookSnapshot struct {
+	sequence int64
+	bids     []OrderBookLevel
+	asks     []OrderBookLevel
+}
+
+// orderBookDelta Changes between sequence start and end. Each change carries its own sequence
+type orderBookDelta struct {
+	start int64
+	end   int64
+	bids  []OrderBookLevel
+	asks  []OrderBookLevel
+}
+
+// NewSpotOrderBook Create a local order book of the spot symbol.
+// The snapshot is fetched with SpotOrderBook, so kc must be set with the API key.
+func NewSpotOrderBook(kc *Kucoin, ws *WsClient, symbol string) *LocalOrderBook {
+	b := newLocalOrderBook(ws, symbol, fmt.Sprintf(TopicSpotLevel2, symbol))
+	b.subscribe = func() error {
+		return ws.SubscribeSpotLevel2(func(e *SpotLevel2Event) {
+			b.update(&orderBookDelta{start: e.SequenceStart, end: e.SequenceEnd, bids: e.Changes.Bids, asks: e.Changes.Asks})
+		}, symbol)
+	}
+	b.snapshot = func() (*orderBookSnapshot, error) {
+		data, err := kc.SpotOrderBook(symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &orderBookSnapshot{sequence: data.Sequence, bids: data.Bids, asks: data.Asks}, nil
+	}
+	return b
+}
+
+// NewFutureOrderBook Create a local order book of the future symbol
+func NewFutureOrderBook(kc *Kucoin, ws *WsClient, symbol string) *LocalOrderBook {
+	b := newLocalOrderBook(ws, symbol, fmt.Sprintf(TopicFutureLevel2, symbol))
+	b.subscribe = func() error {
+		return ws.SubscribeFutureLevel2(func(e *FutureLevel2Event) {
+			level := OrderBookLevel{Price: e.Price, Size: decimal.NewFromInt(int64(e.Size)), Sequence: e.Sequence}
+			delta := &orderBookDelta{start: e.Sequence, end: e.Sequence}
+			if e.Side == "buy" {
+				delta.bids = []OrderBookLevel{level}
+			} else {
+				delta.asks = []OrderBookLevel{level}
+			}
+			b.update(delta)
+		}, symbol)
+	}
+	b.snapshot = func() (*orderBookSnapshot, error) {
+		data, err := kc.FutureOrderBook(symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &orderBookSnapshot{sequence: data.Sequence, bids: data.Bids, asks: data.Asks}, nil
+	}
+	return b
+}
+
+func newLocalOrderBook(ws *WsClient, symbol, topic string) *LocalOrderBook {
+	return &LocalOrderBook{
+		symbol:    symbol,
+		topic:     topic,
+		ws:        ws,
+		retryWait: time.Second,
+		changes:   make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Start Subscribe to the level2 stream and synchronize the book with the REST snapshot
+func (b *LocalOrderBook) Start() error {
+	// Buffer the deltas received before the snapshot
+	b.mu.Lock()
+	b.syncing = true
+	b.mu.Unlock()
+	if err := b.subscribe(); err != nil {
+		b.mu.Lock()
+		b.syncing = false
+		b.mu.Unlock()
+		return err
+	}
+	err := b.sync()
+	if err == errOrderBookStale {
+		go b.resync()
+		return nil
+	}
+	if err != nil {
+		b.mu.Lock()
+		b.syncing = false
+		b.buffer = nil
+		b.mu.Unlock()
+		b.ws.Unsubscribe(b.topic)
+		return err
+	}
+	return nil
+}
+
+// Close Unsubscribe from the level2 stream
+func (b *LocalOrderBook) Close() error {
+	b.mu.Lock()
+	select {
+	case <-b.closed:
+		b.mu.Unlock()
+		return nil
+	default:
+	}
+	close(b.closed)
+	b.synced = false
+	b.mu.Unlock()
+	return b.ws.Unsubscribe(b.topic)
+}
+
+// Symbol Return the symbol of the book
+func (b *LocalOrderBook) Symbol() string {
+	return b.symbol
+}
+
+// Changes Return a channel which receives a value after the book is changed.
+// Notifications are coalesced, so a receive may stand for several changes.
+func (b *LocalOrderBook) Changes() <-chan struct{} {
+	return b.changes
+}
+
+// Synced Return whether the book is synchronized with the server
+func (b *LocalOrderBook) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// Sequence Return the sequence of the last applied change
+func (b *LocalOrderBook) Sequence() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.sequence
+}
+
+// BestBid Return the highest bid
+func (b *LocalOrderBook) BestBid() (OrderBookLevel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return OrderBookLevel{}, ErrOrderBookNotSynced
+	}
+	if len(b.bids) == 0 {
+		return OrderBookLevel{}, ErrOrderBookDepth
+	}
+	return b.bids[0], nil
+}
+
+// BestAsk Return the lowest ask
+func (b *LocalOrderBook) BestAsk() (OrderBookLevel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return OrderBookLevel{}, ErrOrderBookNotSynced
+	}
+	if len(b.asks) == 0 {
+		return OrderBookLevel{}, ErrOrderBookDepth
+	}
+	return b.asks[0], nil
+}
+
+// Depth Return copies of the best n bids and asks. All levels are returned if n <= 0
+func (b *LocalOrderBook) Depth(n int) (bids, asks []OrderBookLevel, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil, nil, ErrOrderBookNotSynced
+	}
+	return copyLevels(b.bids, n), copyLevels(b.asks, n), nil
+}
+
+func copyLevels(levels []OrderBookLevel, n int) []OrderBookLevel {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	result := make([]OrderBookLevel, n)
+	copy(result, levels[:n])
+	return result
+}
+
+// VWAP Return the volume weighted average price of taking size from the book.
+// side is the taker side: buy takes the asks, sell takes the bids.
+func (b *LocalOrderBook) VWAP(side Side, size decimal.Decimal) (decimal.Decimal, error) {
+	if !side.Valid() {
+		return decimal.Zero, fmt.Errorf("unknown side %s", side)
+	}
+	if !size.IsPositive() {
+		return decimal.Zero, errors.New("size must be positive")
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return decimal.Zero, ErrOrderBookNotSynced
+	}
+
+	levels := b.asks
+	if side == SideSell {
+		levels = b.bids
+	}
+	remain, funds := size, decimal.Zero
+	for _, level := range levels {
+		take := decimal.Min(remain, level.Size)
+		funds = funds.Add(take.Mul(level.Price))
+		remain = remain.Sub(take)
+		if remain.IsZero() {
+			return funds.Div(size), nil
+		}
+	}
+	return decimal.Zero, ErrOrderBookDepth
+}
+
+// update Apply the delta, or buffer it while the book is synchronizing
+func (b *LocalOrderBook) update(delta *orderBookDelta) {
+	b.mu.Lock()
+	if b.syncing {
+		if len(b.buffer) >= orderBookMaxBuffer {
+			// The synchronization takes too long, drop the buffered deltas.
+			// A snapshot older than delta is then stale and fetched again
+			b.buffer = []*orderBookDelta{delta}
+		} else {
+			b.buffer = append(b.buffer, delta)
+		}
+		b.mu.Unlock()
+		return
+	}
+	if !b.synced {
+		b.mu.Unlock()
+		return
+	}
+	if delta.end <= b.sequence {
+		b.mu.Unlock()
+		return
+	}
+	if delta.start > b.sequence+1 {
+		// Some deltas are missing, synchronize again
+		b.synced = false
+		b.syncing = true
+		b.buffer = append(b.buffer[:0], delta)
+		b.mu.Unlock()
+		go b.resync()
+		return
+	}
+	b.apply(delta)
+	b.mu.Unlock()
+	b.notify()
+}
+
+// sync Fetch the snapshot and apply the buffered deltas. b.syncing must be set before calling
+func (b *LocalOrderBook) sync() error {
+	snapshot, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	select {
+	case <-b.closed:
+		b.mu.Unlock()
+		return nil
+	default:
+	}
+	b.bids = sortLevels(snapshot.bids, true)
+	b.asks = sortLevels(snapshot.asks, false)
+	b.sequence = snapshot.sequence
+	for _, delta := range b.buffer {
+		if delta.end <= b.sequence {
+			continue
+		}
+		if delta.start > b.sequence+1 {
+			// The snapshot is older than the buffered deltas, keep buffering and fetch a newer one
+			b.mu.Unlock()
+			return errOrderBookStale
+		}
+		b.apply(delta)
+	}
+	b.buffer = nil
+	b.syncing = false
+	b.synced = true
+	b.mu.Unlock()
+	b.notify()
+	return nil
+}
+
+// resync Synchronize again until it succeeds or the book is closed
+func (b *LocalOrderBook) resync() {
+	for {
+		err := b.sync()
+		if err == nil {
+			return
+		}
+		b.ws.logError(fmt.Sprintf("info:order_book_sync\tsymbol:%s\terror:%v", b.symbol, err))
+		select {
+		case <-b.closed:
+			return
+		case <-time.After(b.retryWait):
+		}
+	}
+}
+
+// apply Apply the changes newer than the book. b.mu must be held
+func (b *LocalOrderBook) apply(delta *orderBookDelta) {
+	for _, level := range delta.bids {
+		if level.Sequence == 0 || level.Sequence > b.sequence {
+			b.bids = setLevel(b.bids, level, true)
+		}
+	}
+	for _, level := range delta.asks {
+		if level.Sequence == 0 || level.Sequence > b.sequence {
+			b.asks = setLevel(b.asks, level, false)
+		}
+	}
+	b.sequence = delta.end
+}
+
+func (b *LocalOrderBook) notify() {
+	select {
+	case b.changes <- struct{}{}:
+	default:
+	}
+}
+
+func sortLevels(levels []OrderBookLevel, desc bool) []OrderBookLevel {
+	result := make([]OrderBookLevel, 0, len(levels))
+	for _, level := range levels {
+		if level.Size.IsPositive() {
+			result = append(result, OrderBookLevel{Price: level.Price, Size: level.Size})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if desc {
+			return result[i].Price.GreaterThan(result[j].Price)
+		}
+		return result[i].Price.LessThan(result[j].Price)
+	})
+	return result
+}
+
+// setLevel Set the size of the price level, a zero size removes the level.
+// A zero price only advances the sequence and is ignored.
+func setLevel(levels []OrderBookLevel, level OrderBookLevel, desc bool) []OrderBookLevel {
+	if level.Price.IsZero() {
+		return levels
+	}
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price.LessThanOrEqual(level.Price)
+		}
+		return levels[i].Price.GreaterThanOrEqual(level.Price)
+	})
+	exists := i < len(levels) && levels[i].Price.Equal(level.Price)
+	switch {
+	case level.Size.IsZero():
+		if exists {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+	case exists:
+		levels[i].Size = level.Size
+	default:
+		levels = append(levels, OrderBookLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = OrderBookLevel{Price: level.Price, Size: level.Size}
+	}
+	return levels
+}
@@ -0,0 +1,609 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOrderNotInBook is returned when the order is not resting in the level3 order book
+var ErrOrderNotInBook = errors.New("order is not in the order book")
+
+// Level3OrderBook A level3 order book maintained from the /spotMarket/level3 stream.
+// Every resting order is tracked by id in time priority at its price level, and the level2
+// aggregates are derived from the orders. Messages are buffered while the REST snapshot is fetched,
+// then applied by sequence. When a gap in the sequence is detected, the book is synchronized again.
+// It is safe for concurrent use.
+type Level3OrderBook struct {
+	symbol    string
+	topic     string
+	ws        *WsClient
+	subscribe func() error
+	snapshot  func() (*SpotOrderBookLevel3Data, error)
+	retryWait time.Duration
+
+	mu       sync.RWMutex
+	orders   map[string]*OrderBookOrder
+	bids     []*level3PriceLevel // Sorted by price descending
+	asks     []*level3PriceLevel // Sorted by price ascending
+	sequence int64
+	synced   bool
+	syncing  bool
+	buffer   []*SpotLevel3Event
+	tracked  map[string]*Level3QueueEvent // Last queue position of the tracked orders, nil if not known yet
+	onTrade  func(*Level3TradeEvent)
+	onQueue  func(*Level3QueueEvent)
+	changes  chan struct{}
+	closed   chan struct{}
+}
+
+// level3PriceLevel Orders at the same price in time priority
+type level3PriceLevel struct {
+	price  decimal.Decimal
+	orders []*OrderBookOrder
+}
+
+// Level3TradeEvent A trade matched in the level3 book
+type Level3TradeEvent struct {
+	Symbol       string
+	Sequence     int64
+	TradeId      string
+	Side         Side // Side of the taker
+	Price        decimal.Decimal
+	Size         decimal.Decimal
+	MakerOrderId string
+	TakerOrderId string
+	Ts           int64 // nanosecond
+}
+
+// Level3QueueEvent The queue position of a tracked order
+type Level3QueueEvent struct {
+	OrderId  string
+	Side     Side
+	Price    decimal.Decimal
+	Size     decimal.Decimal // Remaining size of the order
+	Position int             // Number of orders ahead at the same price
+	Ahead    decimal.Decimal // Total size of the orders ahead at the same price
+	Done     bool            // The order left the book, the event is the last one of the order
+	Sequence int64
+}
+
+// NewLevel3OrderBook Create a level3 order book of the spot symbol.
+// The snapshot is fetched with SpotOrderBookLevel3, so kc must be set with the API key.
+func NewLevel3OrderBook(kc *Kucoin, ws *WsClient, symbol string) *Level3OrderBook {
+	b := &Level3OrderBook{
+		symbol:    symbol,
+		topic:     fmt.Sprintf(TopicSpotLevel3, symbol),
+		ws:        ws,
+		retryWait: time.Second,
+		orders:    map[string]*OrderBookOrder{},
+		tracked:   map[string]*Level3QueueEvent{},
+		changes:   make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+	}
+	b.subscribe = func() error {
+		return ws.SubscribeSpotLevel3(b.update, symbol)
+	}
+	b.snapshot = func() (*SpotOrderBookLevel3Data, error) {
+		return kc.SpotOrderBookLevel3(symbol)
+	}
+	return b
+}
+
+// OnTrade Set the handler of the trades. It is called in the goroutine of the WebSocket client,
+// so it should return quickly. Set it before Start to receive every trade
+func (b *Level3OrderBook) OnTrade(handler func(*Level3TradeEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onTrade = handler
+}
+
+// OnQueue Set the handler of the queue position of the tracked orders.
+// It is called in the goroutine of the WebSocket client, so it should return quickly
+func (b *Level3OrderBook) OnQueue(handler func(*Level3QueueEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onQueue = handler
+}
+
+// Track Emit queue position events of the order until it leaves the book.
+// An order which is not in the book yet is reported once it is opened
+func (b *Level3OrderBook) Track(orderId string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.tracked[orderId]; !ok {
+		b.tracked[orderId] = nil
+	}
+}
+
+// Untrack Stop emitting queue position events of the order
+func (b *Level3OrderBook) Untrack(orderId string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tracked, orderId)
+}
+
+// Start Subscribe to the level3 stream and synchronize the book with the REST snapshot
+func (b *Level3OrderBook) Start() error {
+	// Buffer the messages received before the snapshot
+	b.mu.Lock()
+	b.syncing = true
+	b.mu.Unlock()
+	if err := b.subscribe(); err != nil {
+		b.mu.Lock()
+		b.syncing = false
+		b.mu.Unlock()
+		return err
+	}
+	err := b.sync()
+	if err == errOrderBookStale {
+		go b.resync()
+		return nil
+	}
+	if err != nil {
+		b.mu.Lock()
+		b.syncing = false
+		b.buffer = nil
+		b.mu.Unlock()
+		b.ws.Unsubscribe(b.topic)
+		return err
+	}
+	return nil
+}
+
+// Close Unsubscribe from the level3 stream
+func (b *Level3OrderBook) Close() error {
+	b.mu.Lock()
+	select {
+	case <-b.closed:
+		b.mu.Unlock()
+		return nil
+	default:
+	}
+	close(b.closed)
+	b.synced = false
+	b.mu.Unlock()
+	return b.ws.Unsubscribe(b.topic)
+}
+
+// Symbol Return the symbol of the book
+func (b *Level3OrderBook) Symbol() string {
+	return b.symbol
+}
+
+// Changes Return a channel which receives a value after the book is changed.
+// Notifications are coalesced, so a receive may stand for several changes.
+func (b *Level3OrderBook) Changes() <-chan struct{} {
+	return b.changes
+}
+
+// Synced Return whether the book is synchronized with the server
+func (b *Level3OrderBook) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// Sequence Return the sequence of the last applied message
+func (b *Level3OrderBook) Sequence() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.sequence
+}
+
+// Order Return a copy of the resting order
+func (b *Level3OrderBook) Order(orderId string) (OrderBookOrder, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return OrderBookOrder{}, ErrOrderBookNotSynced
+	}
+	order, ok := b.orders[orderId]
+	if !ok {
+		return OrderBookOrder{}, ErrOrderNotInBook
+	}
+	return *order, nil
+}
+
+// Orders Return copies of the orders at the price level in time priority
+func (b *Level3OrderBook) Orders(side Side, price decimal.Decimal) ([]OrderBookOrder, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil, ErrOrderBookNotSynced
+	}
+	levels := b.levels(side)
+	i, ok := searchLevel3(levels, price, side == SideBuy)
+	if !ok {
+		return nil, nil
+	}
+	result := make([]OrderBookOrder, len(levels[i].orders))
+	for j, order := range levels[i].orders {
+		result[j] = *order
+	}
+	return result, nil
+}
+
+// QueuePosition Return the queue position of the resting order
+func (b *Level3OrderBook) QueuePosition(orderId string) (*Level3QueueEvent, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil, ErrOrderBookNotSynced
+	}
+	position := b.queuePosition(orderId)
+	if position == nil {
+		return nil, ErrOrderNotInBook
+	}
+	return position, nil
+}
+
+// BestBid Return the highest bid level
+func (b *Level3OrderBook) BestBid() (OrderBookLevel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return OrderBookLevel{}, ErrOrderBookNotSynced
+	}
+	if len(b.bids) == 0 {
+		return OrderBookLevel{}, ErrOrderBookDepth
+	}
+	return b.bids[0].aggregate(), nil
+}
+
+// BestAsk Return the lowest ask level
+func (b *Level3OrderBook) BestAsk() (OrderBookLevel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return OrderBookLevel{}, ErrOrderBookNotSynced
+	}
+	if len(b.asks) == 0 {
+		return OrderBookLevel{}, ErrOrderBookDepth
+	}
+	return b.asks[0].aggregate(), nil
+}
+
+// Depth Return the best n bid and ask levels aggregated from the orders. All levels are returned if n <= 0
+func (b *Level3OrderBook) Depth(n int) (bids, asks []OrderBookLevel, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil, nil, ErrOrderBookNotSynced
+	}
+	return aggregateLevels(b.bids, n), aggregateLevels(b.asks, n), nil
+}
+
+func aggregateLevels(levels []*level3PriceLevel, n int) []OrderBookLevel {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	result := make([]OrderBookLevel, n)
+	for i := range result {
+		result[i] = levels[i].aggregate()
+	}
+	return result
+}
+
+func (l *level3PriceLevel) aggregate() OrderBookLevel {
+	size := decimal.Zero
+	for _, order := range l.orders {
+		size = size.Add(order.Size)
+	}
+	return OrderBookLevel{Price: l.price, Size: size}
+}
+
+// update Apply the message, or buffer it while the book is synchronizing
+func (b *Level3OrderBook) update(event *SpotLevel3Event) {
+	b.mu.Lock()
+	if b.syncing {
+		if len(b.buffer) >= orderBookMaxBuffer {
+			// The synchronization takes too long, drop the buffered messages.
+			// A snapshot older than event is then stale and fetched again
+			b.buffer = []*SpotLevel3Event{event}
+		} else {
+			b.buffer = append(b.buffer, event)
+		}
+		b.mu.Unlock()
+		return
+	}
+	if !b.synced || event.Sequence <= b.sequence {
+		b.mu.Unlock()
+		return
+	}
+	if event.Sequence > b.sequence+1 {
+		// Some messages are missing, synchronize again
+		b.synced = false
+		b.syncing = true
+		b.buffer = append(b.buffer[:0], event)
+		b.mu.Unlock()
+		go b.resync()
+		return
+	}
+	trades, queues := b.apply(event, nil, nil)
+	onTrade, onQueue := b.onTrade, b.onQueue
+	b.mu.Unlock()
+	b.emit(onTrade, onQueue, trades, queues)
+	b.notify()
+}
+
+// sync Fetch the snapshot and apply the buffered messages. b.syncing must be set before calling
+func (b *Level3OrderBook) sync() error {
+	snapshot, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	select {
+	case <-b.closed:
+		b.mu.Unlock()
+		return nil
+	default:
+	}
+	b.orders = map[string]*OrderBookOrder{}
+	b.bids, b.asks = nil, nil
+	b.sequence = snapshot.Sequence
+	b.seed(snapshot.Bids, SideBuy)
+	b.seed(snapshot.Asks, SideSell)
+
+	var trades []*Level3TradeEvent
+	var queues []*Level3QueueEvent
+	for _, event := range b.buffer {
+		if event.Sequence <= b.sequence {
+			continue
+		}
+		if event.Sequence > b.sequence+1 {
+			// The snapshot is older than the buffered messages, keep buffering and fetch a newer one
+			b.mu.Unlock()
+			return errOrderBookStale
+		}
+		trades, queues = b.apply(event, trades, queues)
+	}
+	b.buffer = nil
+	b.syncing = false
+	b.synced = true
+	// Report the tracked orders which are in the snapshot
+	for orderId := range b.tracked {
+		queues = b.trackQueue(orderId, queues)
+	}
+	onTrade, onQueue := b.onTrade, b.onQueue
+	b.mu.Unlock()
+	b.emit(onTrade, onQueue, trades, queues)
+	b.notify()
+	return nil
+}
+
+// seed Add the orders of the snapshot in time priority. b.mu must be held
+func (b *Level3OrderBook) seed(orders []OrderBookOrder, side Side) {
+	sorted := make([]OrderBookOrder, len(orders))
+	copy(sorted, orders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+	for i := range sorted {
+		order := sorted[i]
+		order.Side = side
+		b.open(&order)
+	}
+}
+
+// resync Synchronize again until it succeeds or the book is closed
+func (b *Level3OrderBook) resync() {
+	for {
+		err := b.sync()
+		if err == nil {
+			return
+		}
+		b.ws.logError(fmt.Sprintf("info:order_book_sync\tsymbol:%s\terror:%v", b.symbol, err))
+		select {
+		case <-b.closed:
+			return
+		case <-time.After(b.retryWait):
+		}
+	}
+}
+
+// apply Apply the message and append the resulting events. b.mu must be held
+func (b *Level3OrderBook) apply(event *SpotLevel3Event, trades []*Level3TradeEvent, queues []*Level3QueueEvent) ([]*Level3TradeEvent, []*Level3QueueEvent) {
+	b.sequence = event.Sequence
+	switch event.Subject {
+	case SubjectLevel3Open:
+		b.open(&OrderBookOrder{OrderId: event.OrderId, Side: event.Side, Price: event.Price, Size: event.Size, Time: event.OrderTime})
+		queues = b.levelQueues(event.Side, event.Price, queues)
+	case SubjectLevel3Update:
+		if order, ok := b.orders[event.OrderId]; ok {
+			order.Size = event.Size
+			queues = b.levelQueues(order.Side, order.Price, queues)
+		}
+	case SubjectLevel3Match:
+		trades = append(trades, &Level3TradeEvent{
+			Symbol:       event.Symbol,
+			Sequence:     event.Sequence,
+			TradeId:      event.TradeId,
+			Side:         event.Side,
+			Price:        event.Price,
+			Size:         event.Size,
+			MakerOrderId: event.MakerOrderId,
+			TakerOrderId: event.TakerOrderId,
+			Ts:           event.Ts,
+		})
+		if order, ok := b.orders[event.MakerOrderId]; ok {
+			order.Size = order.Size.Sub(event.Size)
+			if !order.Size.IsPositive() {
+				queues = b.done(order, queues)
+			}
+			queues = b.levelQueues(order.Side, order.Price, queues)
+		}
+	case SubjectLevel3Done:
+		if order, ok := b.orders[event.OrderId]; ok {
+			queues = b.done(order, queues)
+			queues = b.levelQueues(order.Side, order.Price, queues)
+		}
+	}
+	// received does not change the book, the order is opened by a later message if it rests
+	return trades, queues
+}
+
+func (b *Level3OrderBook) levels(side Side) []*level3PriceLevel {
+	if side == SideBuy {
+		return b.bids
+	}
+	return b.asks
+}
+
+func (b *Level3OrderBook) setLevels(side Side, levels []*level3PriceLevel) {
+	if side == SideBuy {
+		b.bids = levels
+	} else {
+		b.asks = levels
+	}
+}
+
+// open Add the order at the end of the queue of its price level. b.mu must be held
+func (b *Level3OrderBook) open(order *OrderBookOrder) {
+	if order.Price.IsZero() || !order.Size.IsPositive() {
+		return
+	}
+	if _, ok := b.orders[order.OrderId]; ok {
+		return
+	}
+	levels := b.levels(order.Side)
+	desc := order.Side == SideBuy
+	i, ok := searchLevel3(levels, order.Price, desc)
+	if !ok {
+		levels = append(levels, nil)
+		copy(levels[i+1:], levels[i:])
+		levels[i] = &level3PriceLevel{price: order.Price}
+		b.setLevels(order.Side, levels)
+	}
+	levels[i].orders = append(levels[i].orders, order)
+	b.orders[order.OrderId] = order
+}
+
+// done Remove the order from the book. b.mu must be held
+func (b *Level3OrderBook) done(order *OrderBookOrder, queues []*Level3QueueEvent) []*Level3QueueEvent {
+	delete(b.orders, order.OrderId)
+	levels := b.levels(order.Side)
+	if i, ok := searchLevel3(levels, order.Price, order.Side == SideBuy); ok {
+		level := levels[i]
+		for j, o := range level.orders {
+			if o == order {
+				level.orders = append(level.orders[:j], level.orders[j+1:]...)
+				break
+			}
+		}
+		if len(level.orders) == 0 {
+			b.setLevels(order.Side, append(levels[:i], levels[i+1:]...))
+		}
+	}
+	if _, ok := b.tracked[order.OrderId]; ok {
+		delete(b.tracked, order.OrderId)
+		queues = append(queues, &Level3QueueEvent{
+			OrderId:  order.OrderId,
+			Side:     order.Side,
+			Price:    order.Price,
+			Size:     decimal.Zero,
+			Ahead:    decimal.Zero,
+			Done:     true,
+			Sequence: b.sequence,
+		})
+	}
+	return queues
+}
+
+// levelQueues Append the changed queue positions of the tracked orders at the price level. b.mu must be held
+func (b *Level3OrderBook) levelQueues(side Side, price decimal.Decimal, queues []*Level3QueueEvent) []*Level3QueueEvent {
+	if len(b.tracked) == 0 {
+		return queues
+	}
+	levels := b.levels(side)
+	i, ok := searchLevel3(levels, price, side == SideBuy)
+	if !ok {
+		return queues
+	}
+	for _, order := range levels[i].orders {
+		if _, tracked := b.tracked[order.OrderId]; tracked {
+			queues = b.trackQueue(order.OrderId, queues)
+		}
+	}
+	return queues
+}
+
+// trackQueue Append the queue position of the tracked order if it is changed. b.mu must be held
+func (b *Level3OrderBook) trackQueue(orderId string, queues []*Level3QueueEvent) []*Level3QueueEvent {
+	position := b.queuePosition(orderId)
+	if position == nil {
+		return queues
+	}
+	last := b.tracked[orderId]
+	if last != nil && last.Position == position.Position && last.Ahead.Equal(position.Ahead) && last.Size.Equal(position.Size) {
+		return queues
+	}
+	b.tracked[orderId] = position
+	event := *position
+	return append(queues, &event)
+}
+
+// queuePosition Return the queue position of the order, nil if it is not in the book. b.mu must be held
+func (b *Level3OrderBook) queuePosition(orderId string) *Level3QueueEvent {
+	order, ok := b.orders[orderId]
+	if !ok {
+		return nil
+	}
+	levels := b.levels(order.Side)
+	i, ok := searchLevel3(levels, order.Price, order.Side == SideBuy)
+	if !ok {
+		return nil
+	}
+	ahead := decimal.Zero
+	for j, o := range levels[i].orders {
+		if o == order {
+			return &Level3QueueEvent{
+				OrderId:  order.OrderId,
+				Side:     order.Side,
+				Price:    order.Price,
+				Size:     order.Size,
+				Position: j,
+				Ahead:    ahead,
+				Sequence: b.sequence,
+			}
+		}
+		ahead = ahead.Add(o.Size)
+	}
+	return nil
+}
+
+func (b *Level3OrderBook) emit(onTrade func(*Level3TradeEvent), onQueue func(*Level3QueueEvent), trades []*Level3TradeEvent, queues []*Level3QueueEvent) {
+	if onTrade != nil {
+		for _, trade := range trades {
+			onTrade(trade)
+		}
+	}
+	if onQueue != nil {
+		for _, queue := range queues {
+			onQueue(queue)
+		}
+	}
+}
+
+func (b *Level3OrderBook) notify() {
+	select {
+	case b.changes <- struct{}{}:
+	default:
+	}
+}
+
+// searchLevel3 Return the index of the price level, or where it should be inserted
+func searchLevel3(levels []*level3PriceLevel, price decimal.Decimal, desc bool) (int, bool) {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].price.LessThanOrEqual(price)
+		}
+		return levels[i].price.GreaterThanOrEqual(price)
+	})
+	return i, i < len(levels) && levels[i].price.Equal(price)
+}
@@ -0,0 +1,192 @@
+package kugo
+
+import (
+	"context"
+)
+
+// Max page sizes of the paginated endpoints
+const (
+	spotPageSize       = 500
+	futurePageSize     = 1000
+	fundingPageSize    = 500
+	subAccountPageSize = 100
+)
+
+// Pager Iterate the items of a paginated endpoint, fetching one page at a time up to TotalPage.
+// Pages are fetched as Next reaches them, so only one page is held in memory.
+// It waits for the rate limiter of the endpoint and stops when the context is done.
+//
+//	pager := instance.SpotOrderFillsAll(ctx, req)
+//	for pager.Next() {
+//		fill := pager.Item()
+//	}
+//	if err := pager.Err(); err != nil {
+//	}
+type Pager[T any] struct {
+	ctx       context.Context
+	limiter   *rateLimiter
+	pageSize  int
+	fetch     func(currentPage, pageSize int) ([]T, *BaseResponsePagination, error)
+	page      int // The last fetched page
+	totalPage int
+	totalNum  int
+	items     []T
+	i         int
+	item      T
+	done      bool
+	err       error
+}
+
+func newPager[T any](ctx context.Context, limiter *rateLimiter, pageSize int, fetch func(currentPage, pageSize int) ([]T, *BaseResponsePagination, error)) *Pager[T] {
+	return &Pager[T]{ctx: ctx, limiter: limiter, pageSize: pageSize, fetch: fetch}
+}
+
+// Next Advance to the next item, fetching the next page if needed.
+// It returns false when there are no more items or an error occurs, see Err
+func (p *Pager[T]) Next() bool {
+	for p.i >= len(p.items) {
+		if p.done || p.err != nil {
+			return false
+		}
+		if p.page > 0 && p.page >= p.totalPage {
+			p.done = true
+			return false
+		}
+		if p.err = p.ctx.Err(); p.err != nil {
+			return false
+		}
+		if p.limiter != nil {
+			if p.err = p.limiter.ready(p.ctx); p.err != nil {
+				return false
+			}
+		}
+		items, page, err := p.fetch(p.page+1, p.pageSize)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page++
+		p.totalPage, p.totalNum = page.TotalPage, page.TotalNum
+		p.items, p.i = items, 0
+		if len(items) == 0 {
+			p.done = true
+		}
+	}
+	p.item = p.items[p.i]
+	p.i++
+	return true
+}
+
+// Item Return the current item
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err Return the error which stopped the iteration, nil if it reached the end
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// TotalNum Return the total number of items reported by the last fetched page
+func (p *Pager[T]) TotalNum() int {
+	return p.totalNum
+}
+
+// SpotOrderListAll Iterate the orders of every page of SpotOrderList
+func (kc *Kucoin) SpotOrderListAll(ctx context.Context, req *SpotOrderListRequest) *Pager[SpotOrderOneData] {
+	return newPager(ctx, kc.spotLimiter, spotPageSize, func(currentPage, pageSize int) ([]SpotOrderOneData, *BaseResponsePagination, error) {
+		data, err := kc.SpotOrderList(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// SpotOrderFillsAll Iterate the fills of every page of SpotOrderFills
+func (kc *Kucoin) SpotOrderFillsAll(ctx context.Context, req *SpotOrderFillsRequest) *Pager[SpotOrderFillsItem] {
+	return newPager(ctx, kc.spotLimiter, spotPageSize, func(currentPage, pageSize int) ([]SpotOrderFillsItem, *BaseResponsePagination, error) {
+		data, err := kc.SpotOrderFills(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// SpotLedgersAll Iterate the ledger entries of every page of SpotLedgers
+func (kc *Kucoin) SpotLedgersAll(ctx context.Context, req *SpotLedgersRequest) *Pager[LedgerItem] {
+	return newPager(ctx, kc.spotLimiter, spotPageSize, func(currentPage, pageSize int) ([]LedgerItem, *BaseResponsePagination, error) {
+		data, err := kc.SpotLedgers(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// FutureOrderListAll Iterate the orders of every page of FutureOrderList
+func (kc *Kucoin) FutureOrderListAll(ctx context.Context, req *FutureOrderListRequest) *Pager[FutureOrderOneData] {
+	return newPager(ctx, kc.futureLimiter, futurePageSize, func(currentPage, pageSize int) ([]FutureOrderOneData, *BaseResponsePagination, error) {
+		data, err := kc.FutureOrderList(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// FutureOrderFillsAll Iterate the fills of every page of FutureOrderFills
+func (kc *Kucoin) FutureOrderFillsAll(ctx context.Context, req *FutureOrderFillsRequest) *Pager[FutureOrderFillsItem] {
+	return newPager(ctx, kc.futureLimiter, futurePageSize, func(currentPage, pageSize int) ([]FutureOrderFillsItem, *BaseResponsePagination, error) {
+		data, err := kc.FutureOrderFills(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// DepositListAll Iterate the deposits of every page of DepositList
+func (kc *Kucoin) DepositListAll(ctx context.Context, req *DepositListRequest) *Pager[DepositItem] {
+	return newPager(ctx, kc.spotLimiter, fundingPageSize, func(currentPage, pageSize int) ([]DepositItem, *BaseResponsePagination, error) {
+		data, err := kc.DepositList(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// WithdrawalListAll Iterate the withdrawals of every page of WithdrawalList
+func (kc *Kucoin) WithdrawalListAll(ctx context.Context, req *WithdrawalListRequest) *Pager[WithdrawalItem] {
+	return newPager(ctx, kc.spotLimiter, fundingPageSize, func(currentPage, pageSize int) ([]WithdrawalItem, *BaseResponsePagination, error) {
+		data, err := kc.WithdrawalList(req, currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// SubUserListAll Iterate the sub users of every page of SubUserList
+func (kc *Kucoin) SubUserListAll(ctx context.Context) *Pager[SubUserData] {
+	return newPager(ctx, kc.spotLimiter, subAccountPageSize, func(currentPage, pageSize int) ([]SubUserData, *BaseResponsePagination, error) {
+		data, err := kc.SubUserList(currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
+
+// SubAccountListV2All Iterate the sub accounts of every page of SubAccountListV2
+func (kc *Kucoin) SubAccountListV2All(ctx context.Context) *Pager[SubAccountData] {
+	return newPager(ctx, kc.spotLimiter, subAccountPageSize, func(currentPage, pageSize int) ([]SubAccountData, *BaseResponsePagination, error) {
+		data, err := kc.SubAccountListV2(currentPage, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data.Items, &data.BaseResponsePagination, nil
+	})
+}
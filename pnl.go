@@ -0,0 +1,496 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cost methods of PnLTracker
+const (
+	CostFIFO    = "fifo"    // Close the oldest lots first
+	CostLIFO    = "lifo"    // Close the newest lots first
+	CostAverage = "average" // Close at the average cost of the position
+)
+
+const (
+	pnlTradeWindow   = historyWindow // How long the trade ids are kept after the newest fill
+	pnlPruneInterval = time.Hour     // How often the trade ids are pruned, in the time of the fills
+)
+
+// PnLOption Option of PnLTracker
+type PnLOption func(t *PnLTracker) error
+
+// SetPnLCostMethod Set the cost method, CostFIFO by default
+func SetPnLCostMethod(method string) PnLOption {
+	return func(t *PnLTracker) error {
+		if t == nil {
+			return errors.New("tracker is nil")
+		}
+		if method != CostFIFO && method != CostLIFO && method != CostAverage {
+			return fmt.Errorf("unknown cost method %s", method)
+		}
+		t.method = method
+		return nil
+	}
+}
+
+// SetPnLContracts Set the lookup of the contracts, which is required to track future fills,
+// e.g. instance.Rules().Future
+func SetPnLContracts(lookup func(symbol string) (*FutureSymbolData, error)) PnLOption {
+	return func(t *PnLTracker) error {
+		if t == nil {
+			return errors.New("tracker is nil")
+		}
+		t.contracts = lookup
+		return nil
+	}
+}
+
+// SetPnLReportCurrency Also report the PnL and fees in the currency, converted with rate,
+// which returns the price of from in to
+func SetPnLReportCurrency(currency string, rate func(from, to string) (decimal.Decimal, error)) PnLOption {
+	return func(t *PnLTracker) error {
+		if t == nil {
+			return errors.New("tracker is nil")
+		}
+		if rate == nil {
+			return errors.New("rate is nil")
+		}
+		t.reportCurrency = currency
+		t.rate = rate
+		return nil
+	}
+}
+
+// PnLPosition The position and PnL of a symbol computed by PnLTracker
+type PnLPosition struct {
+	Symbol     string
+	Market     string          // InstrumentSpot or InstrumentFuture
+	Currency   string          // Currency of the PnL: the quote currency of spot symbols, the settle currency of contracts
+	Qty        decimal.Decimal // Positive when long and negative when short. Base currency for spot, contracts for futures
+	AvgCost    decimal.Decimal // Average entry price of the open lots
+	Price      decimal.Decimal // The price of SetPrice or of the last fill
+	Realized   decimal.Decimal
+	Unrealized decimal.Decimal            // PnL of closing the position at Price
+	Fees       map[string]decimal.Decimal // Paid fees by currency
+
+	ReportCurrency   string // Currency of SetPnLReportCurrency
+	RealizedReport   decimal.Decimal
+	UnrealizedReport decimal.Decimal
+	FeesReport       decimal.Decimal
+}
+
+// pnlLot An open lot of a position
+type pnlLot struct {
+	qty   decimal.Decimal // Always positive, the side is the side of the position
+	price decimal.Decimal
+}
+
+type pnlPosition struct {
+	symbol     string
+	market     string
+	currency   string
+	multiplier decimal.Decimal // Base currency of a contract, 1 for spot
+	inverse    bool
+	long       bool
+	lots       []pnlLot
+	price      decimal.Decimal
+	realized   decimal.Decimal
+	fees       map[string]decimal.Decimal
+}
+
+// PnLTracker Track the positions of spot symbols and contracts from fills, and compute the
+// average cost, realized and unrealized PnL and fees with a cost method.
+// Fills are loaded from REST with LoadSpotFills and LoadFutureFills and streamed with OnSpotOrder
+// and OnFutureOrder, deduplicated by the tradeId of the symbol, and must be added in time order.
+// The trade ids are kept for 7 days after the newest fill, the window of the fills endpoints,
+// so a fill older than that must not be added again.
+// Order events carry no fees, so the fee of a streamed fill is only counted when the same fill
+// is loaded from REST. It is safe for concurrent use.
+type PnLTracker struct {
+	method         string
+	contracts      func(symbol string) (*FutureSymbolData, error)
+	reportCurrency string
+	rate           func(from, to string) (decimal.Decimal, error)
+
+	mu        sync.Mutex
+	positions map[string]*pnlPosition
+	trades    map[string]pnlTrade // Key is market:symbol:tradeId
+	newest    int64               // Time of the newest fill in milliseconds
+	pruned    int64               // Time of the newest fill when the trades were last pruned
+}
+
+// pnlTrade An added trade
+type pnlTrade struct {
+	time     int64 // millisecond
+	feeKnown bool  // Whether the fee of the trade is counted
+}
+
+// NewPnLTracker Create a tracker without positions
+func NewPnLTracker(opts ...PnLOption) (*PnLTracker, error) {
+	t := &PnLTracker{
+		method:    CostFIFO,
+		positions: map[string]*pnlPosition{},
+		trades:    map[string]pnlTrade{},
+	}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// pnlFill A fill of a spot symbol or a contract
+type pnlFill struct {
+	tradeId     string
+	time        int64 // millisecond
+	side        string
+	price       decimal.Decimal
+	size        decimal.Decimal
+	fee         decimal.Decimal
+	feeCurrency string
+	feeKnown    bool
+}
+
+// AddSpotFill Add a fill of GET /api/v1/fills
+func (t *PnLTracker) AddSpotFill(f *SpotOrderFillsItem) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.spotPosition(f.Symbol)
+	if err != nil {
+		return err
+	}
+	return t.add(p, &pnlFill{tradeId: f.TradeId, time: f.CreatedAt, side: f.Side, price: f.Price, size: f.Size,
+		fee: f.Fee, feeCurrency: f.FeeCurrency, feeKnown: true})
+}
+
+// AddFutureFill Add a fill of GET /api/v1/fills of futures
+func (t *PnLTracker) AddFutureFill(f *FutureOrderFillsItem) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.futurePosition(f.Symbol)
+	if err != nil {
+		return err
+	}
+	return t.add(p, &pnlFill{tradeId: f.TradeId, time: f.CreatedAt, side: f.Side, price: f.Price,
+		size: decimal.NewFromInt(int64(f.Size)), fee: f.Fee, feeCurrency: f.FeeCurrency, feeKnown: true})
+}
+
+// OnSpotOrder Add the fill of a match event of SubscribeSpotOrders or SubscribeSpotOrdersV2
+func (t *PnLTracker) OnSpotOrder(e *SpotOrderEvent) error {
+	if e.Type != "match" {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.spotPosition(e.Symbol)
+	if err != nil {
+		return err
+	}
+	return t.add(p, &pnlFill{tradeId: e.TradeId, time: e.Ts / int64(time.Millisecond), side: e.Side,
+		price: e.MatchPrice, size: e.MatchSize})
+}
+
+// OnFutureOrder Add the fill of a match event of SubscribeFutureOrders
+func (t *PnLTracker) OnFutureOrder(e *FutureOrderEvent) error {
+	if e.Type != "match" {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.futurePosition(e.Symbol)
+	if err != nil {
+		return err
+	}
+	return t.add(p, &pnlFill{tradeId: e.TradeId, time: e.Ts / int64(time.Millisecond), side: e.Side,
+		price: e.MatchPrice, size: e.MatchSize})
+}
+
+// LoadSpotFills Add the fills of the history, e.g. of SpotOrderFillsHistory. It returns the number of fills
+func (t *PnLTracker) LoadSpotFills(history *History[SpotOrderFillsItem]) (int, error) {
+	n := 0
+	for history.Next() {
+		fill := history.Item()
+		if err := t.AddSpotFill(&fill); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, history.Err()
+}
+
+// LoadFutureFills Add the fills of the history, e.g. of FutureOrderFillsHistory. It returns the number of fills
+func (t *PnLTracker) LoadFutureFills(history *History[FutureOrderFillsItem]) (int, error) {
+	n := 0
+	for history.Next() {
+		fill := history.Item()
+		if err := t.AddFutureFill(&fill); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, history.Err()
+}
+
+// SetPrice Set the price of the symbol to compute the unrealized PnL, e.g. the last or mark price
+func (t *PnLTracker) SetPrice(symbol string, price decimal.Decimal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.positions[symbol]; ok {
+		p.price = price
+	}
+}
+
+// Position Return the position of the symbol
+func (t *PnLTracker) Position(symbol string) (*PnLPosition, error) {
+	t.mu.Lock()
+	p, ok := t.positions[symbol]
+	var position *PnLPosition
+	if ok {
+		position = p.snapshot()
+	}
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownSymbol, symbol)
+	}
+	if err := t.report(position); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// Positions Return the positions of every symbol with fills, sorted by symbol
+func (t *PnLTracker) Positions() ([]*PnLPosition, error) {
+	t.mu.Lock()
+	positions := make([]*PnLPosition, 0, len(t.positions))
+	for _, p := range t.positions {
+		positions = append(positions, p.snapshot())
+	}
+	t.mu.Unlock()
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	for _, position := range positions {
+		if err := t.report(position); err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+// spotPosition t.mu must be held
+func (t *PnLTracker) spotPosition(symbol string) (*pnlPosition, error) {
+	if p, ok := t.positions[symbol]; ok {
+		return p, nil
+	}
+	i := strings.LastIndex(symbol, "-")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid spot symbol %s", symbol)
+	}
+	p := &pnlPosition{symbol: symbol, market: InstrumentSpot, currency: symbol[i+1:],
+		multiplier: decimal.NewFromInt(1), fees: map[string]decimal.Decimal{}}
+	t.positions[symbol] = p
+	return p, nil
+}
+
+// futurePosition t.mu must be held
+func (t *PnLTracker) futurePosition(symbol string) (*pnlPosition, error) {
+	if p, ok := t.positions[symbol]; ok {
+		return p, nil
+	}
+	if t.contracts == nil {
+		return nil, errors.New("contracts are not set, see SetPnLContracts")
+	}
+	contract, err := t.contracts(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err = contract.checkConvertible(); err != nil {
+		return nil, err
+	}
+	p := &pnlPosition{symbol: symbol, market: InstrumentFuture, currency: contract.SettleCurrency,
+		multiplier: contract.Multiplier.Abs(), inverse: contract.IsInverse, fees: map[string]decimal.Decimal{}}
+	t.positions[symbol] = p
+	return p, nil
+}
+
+// add t.mu must be held
+func (t *PnLTracker) add(p *pnlPosition, f *pnlFill) error {
+	if f.side != string(SideBuy) && f.side != string(SideSell) {
+		return fmt.Errorf("invalid side %s of trade %s", f.side, f.tradeId)
+	}
+	if f.tradeId != "" {
+		// Trade ids are unique in a symbol only
+		key := p.market + ":" + p.symbol + ":" + f.tradeId
+		trade, ok := t.trades[key]
+		if ok {
+			// The fee of a streamed fill comes with the same fill from REST
+			if !trade.feeKnown && f.feeKnown {
+				trade.feeKnown = true
+				t.trades[key] = trade
+				p.addFee(f)
+			}
+			return nil
+		}
+		t.trades[key] = pnlTrade{time: f.time, feeKnown: f.feeKnown}
+		t.prune(f.time)
+	}
+	if f.feeKnown {
+		p.addFee(f)
+	}
+
+	p.price = f.price
+	buy := f.side == string(SideBuy)
+	size := f.size
+	// Close the open lots of the other side
+	for size.IsPositive() && len(p.lots) > 0 && p.long != buy {
+		i := 0
+		if t.method == CostLIFO {
+			i = len(p.lots) - 1
+		}
+		lot := &p.lots[i]
+		qty := decimal.Min(size, lot.qty)
+		p.realized = p.realized.Add(p.pnl(qty, lot.price, f.price))
+		size = size.Sub(qty)
+		lot.qty = lot.qty.Sub(qty)
+		if lot.qty.IsZero() {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+	if !size.IsPositive() {
+		return nil
+	}
+
+	// Open a lot with the rest
+	if len(p.lots) == 0 {
+		p.long = buy
+	}
+	if t.method == CostAverage && len(p.lots) > 0 {
+		p.lots[0] = p.merge(p.lots[0], pnlLot{qty: size, price: f.price})
+	} else {
+		p.lots = append(p.lots, pnlLot{qty: size, price: f.price})
+	}
+	return nil
+}
+
+// prune Forget the trades older than pnlTradeWindow before the newest fill. t.mu must be held
+func (t *PnLTracker) prune(at int64) {
+	if at > t.newest {
+		t.newest = at
+	}
+	if t.newest-t.pruned < pnlPruneInterval.Milliseconds() {
+		return
+	}
+	t.pruned = t.newest
+	cutoff := t.newest - pnlTradeWindow.Milliseconds()
+	for key, trade := range t.trades {
+		if trade.time < cutoff {
+			delete(t.trades, key)
+		}
+	}
+}
+
+func (p *pnlPosition) addFee(f *pnlFill) {
+	if f.fee.IsZero() {
+		return
+	}
+	currency := f.feeCurrency
+	if currency == "" {
+		currency = p.currency
+	}
+	p.fees[currency] = p.fees[currency].Add(f.fee)
+}
+
+// pnl Return the PnL of closing qty of the position entered at entry at exit
+func (p *pnlPosition) pnl(qty, entry, exit decimal.Decimal) decimal.Decimal {
+	var diff decimal.Decimal
+	if p.inverse {
+		// Inverse contracts are worth multiplier quote currency, the PnL is in the base currency
+		diff = decimal.NewFromInt(1).Div(entry).Sub(decimal.NewFromInt(1).Div(exit))
+	} else {
+		diff = exit.Sub(entry)
+	}
+	if !p.long {
+		diff = diff.Neg()
+	}
+	return qty.Mul(p.multiplier).Mul(diff)
+}
+
+// merge Return the lot of both lots at the average price, which is harmonic for inverse contracts
+func (p *pnlPosition) merge(a, b pnlLot) pnlLot {
+	qty := a.qty.Add(b.qty)
+	if p.inverse {
+		return pnlLot{qty: qty, price: qty.Div(a.qty.Div(a.price).Add(b.qty.Div(b.price)))}
+	}
+	return pnlLot{qty: qty, price: a.qty.Mul(a.price).Add(b.qty.Mul(b.price)).Div(qty)}
+}
+
+// snapshot p must be guarded by the mutex of the tracker
+func (p *pnlPosition) snapshot() *PnLPosition {
+	position := &PnLPosition{
+		Symbol:   p.symbol,
+		Market:   p.market,
+		Currency: p.currency,
+		Price:    p.price,
+		Realized: p.realized,
+		Fees:     make(map[string]decimal.Decimal, len(p.fees)),
+	}
+	for currency, fee := range p.fees {
+		position.Fees[currency] = fee
+	}
+	if len(p.lots) == 0 {
+		return position
+	}
+	avg := p.lots[0]
+	for _, lot := range p.lots[1:] {
+		avg = p.merge(avg, lot)
+	}
+	position.Qty, position.AvgCost = avg.qty, avg.price
+	if !p.long {
+		position.Qty = position.Qty.Neg()
+	}
+	if p.price.IsPositive() {
+		for _, lot := range p.lots {
+			position.Unrealized = position.Unrealized.Add(p.pnl(lot.qty, lot.price, p.price))
+		}
+	}
+	return position
+}
+
+// report Convert the PnL and fees to the report currency
+func (t *PnLTracker) report(position *PnLPosition) error {
+	if t.rate == nil {
+		return nil
+	}
+	position.ReportCurrency = t.reportCurrency
+	rate, err := t.convert(position.Currency)
+	if err != nil {
+		return err
+	}
+	position.RealizedReport = position.Realized.Mul(rate)
+	position.UnrealizedReport = position.Unrealized.Mul(rate)
+	position.FeesReport = decimal.Zero
+	for currency, fee := range position.Fees {
+		if rate, err = t.convert(currency); err != nil {
+			return err
+		}
+		position.FeesReport = position.FeesReport.Add(fee.Mul(rate))
+	}
+	return nil
+}
+
+func (t *PnLTracker) convert(currency string) (decimal.Decimal, error) {
+	if currency == t.reportCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, err := t.rate(currency, t.reportCurrency)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("rate of %s in %s: %w", currency, t.reportCurrency, err)
+	}
+	return rate, nil
+}
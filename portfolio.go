@@ -0,0 +1,239 @@
+package kugo
+
+import (
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+// PortfolioCurrency The currency of the valuation of PortfolioSnapshot
+const PortfolioCurrency = "USDT"
+
+// Accounts of PortfolioBalance
+const (
+	PortfolioMain     = "main"
+	PortfolioTrade    = "trade"
+	PortfolioMargin   = "margin"
+	PortfolioIsolated = "isolated" // Isolated margin, one account per symbol
+	PortfolioFutures  = "futures"
+)
+
+// portfolioAliases Spot currencies of the futures currencies
+var portfolioAliases = map[string]string{"XBT": "BTC"}
+
+// portfolioCurrency Return the spot currency of a currency, e.g. BTC of XBT
+func portfolioCurrency(currency string) string {
+	if alias, ok := portfolioAliases[currency]; ok {
+		return alias
+	}
+	return currency
+}
+
+// PortfolioBalance The balance of a currency in an account, or in all accounts if Account is empty.
+// Futures currencies are named as the spot ones, e.g. BTC for XBT
+type PortfolioBalance struct {
+	Account       string          `json:"account,omitempty"`
+	Symbol        string          `json:"symbol,omitempty"` // Symbol of the isolated margin account
+	Currency      string          `json:"currency"`
+	Balance       decimal.Decimal `json:"balance"` // The account equity for futures, including the unrealised PnL
+	Available     decimal.Decimal `json:"available"`
+	Hold          decimal.Decimal `json:"hold"`          // Position margin, order margin and frozen funds for futures
+	Debt          decimal.Decimal `json:"debt"`          // Liability of the margin accounts, with the interest
+	UnrealisedPNL decimal.Decimal `json:"unrealisedPNL"` // Of the futures account
+	Price         decimal.Decimal `json:"price"`         // Price in USDT, zero if it is unknown
+	Value         decimal.Decimal `json:"value"`         // (Balance - Debt) * Price
+}
+
+// PortfolioSnapshot The balances of the spot, margin and futures accounts valued in USDT.
+// Decimals are encoded as JSON strings, so the snapshot can be exported with json.Marshal
+type PortfolioSnapshot struct {
+	Time          int64              `json:"time"`          // millisecond, time of the tickers
+	Currency      string             `json:"currency"`      // PortfolioCurrency
+	Equity        decimal.Decimal    `json:"equity"`        // Sum of the values, net of debt
+	Debt          decimal.Decimal    `json:"debt"`          // Value of the debt
+	UnrealisedPNL decimal.Decimal    `json:"unrealisedPNL"` // Value of the unrealised PnL of futures, included in Equity
+	DebtRatio     decimal.Decimal    `json:"debtRatio"`     // Of the cross margin account
+	Balances      []PortfolioBalance `json:"balances"`      // By account and currency
+	Currencies    []PortfolioBalance `json:"currencies"`    // By currency, summed over the accounts
+	Unpriced      []string           `json:"unpriced"`      // Currencies without a USDT price, excluded from the values
+	Errors        []PortfolioError   `json:"errors"`        // Accounts which failed to load, excluded from the snapshot
+}
+
+// PortfolioError An account of PortfolioSnapshot which failed to load
+type PortfolioError struct {
+	Account  string `json:"account"`
+	Currency string `json:"currency,omitempty"` // Settle currency of the futures account
+	Error    string `json:"error"`
+}
+
+// Balance Return the balance of the currency in all accounts
+func (s *PortfolioSnapshot) Balance(currency string) (PortfolioBalance, bool) {
+	for _, b := range s.Currencies {
+		if b.Currency == currency {
+			return b, true
+		}
+	}
+	return PortfolioBalance{}, false
+}
+
+// Portfolio Take a snapshot of the spot accounts (SpotAccount), the cross and isolated margin accounts
+// (SpotMarginAccount, SpotIsolatedAccounts) and the futures accounts (FutureAccount) of settleCurrencies,
+// valued with the last prices of SpotAllTickers. Currencies without a USDT symbol are valued through BTC.
+// The futures accounts of all settle currencies of FutureSymbols are included if settleCurrencies is empty.
+// The margin and futures accounts which fail to load, e.g. without the permission of the API key,
+// are listed in Errors and the snapshot is taken without them
+func (kc *Kucoin) Portfolio(settleCurrencies ...string) (*PortfolioSnapshot, error) {
+	accounts, err := kc.SpotAccount("", "")
+	if err != nil {
+		return nil, err
+	}
+	var balances []PortfolioBalance
+	var errs []PortfolioError
+	for _, a := range accounts {
+		// The margin account is listed with its debt below
+		if a.Type == PortfolioMargin || a.Balance.IsZero() {
+			continue
+		}
+		balances = append(balances, PortfolioBalance{Account: a.Type, Currency: a.Currency,
+			Balance: a.Balance, Available: a.Available, Hold: a.Holds})
+	}
+
+	var debtRatio decimal.Decimal
+	if margin, err := kc.SpotMarginAccount(); err != nil {
+		errs = append(errs, PortfolioError{Account: PortfolioMargin, Error: err.Error()})
+	} else {
+		debtRatio = margin.DebtRatio
+		for _, a := range margin.Accounts {
+			if a.TotalBalance.IsZero() && a.Liability.IsZero() {
+				continue
+			}
+			balances = append(balances, PortfolioBalance{Account: PortfolioMargin, Currency: a.Currency,
+				Balance: a.TotalBalance, Available: a.AvailableBalance, Hold: a.HoldBalance, Debt: a.Liability})
+		}
+	}
+
+	if isolated, err := kc.SpotIsolatedAccounts(""); err != nil {
+		errs = append(errs, PortfolioError{Account: PortfolioIsolated, Error: err.Error()})
+	} else {
+		for _, a := range isolated.Assets {
+			for _, asset := range []IsolatedAsset{a.BaseAsset, a.QuoteAsset} {
+				debt := asset.Liability.Add(asset.Interest)
+				if asset.TotalBalance.IsZero() && debt.IsZero() {
+					continue
+				}
+				balances = append(balances, PortfolioBalance{Account: PortfolioIsolated, Symbol: a.Symbol, Currency: asset.Currency,
+					Balance: asset.TotalBalance, Available: asset.AvailableBalance, Hold: asset.HoldBalance, Debt: debt})
+			}
+		}
+	}
+
+	if len(settleCurrencies) == 0 {
+		symbols, err := kc.FutureSymbols()
+		if err != nil {
+			errs = append(errs, PortfolioError{Account: PortfolioFutures, Error: err.Error()})
+		}
+		seen := map[string]bool{}
+		for _, symbol := range symbols {
+			if symbol.SettleCurrency != "" && !seen[symbol.SettleCurrency] {
+				seen[symbol.SettleCurrency] = true
+				settleCurrencies = append(settleCurrencies, symbol.SettleCurrency)
+			}
+		}
+		sort.Strings(settleCurrencies)
+	}
+	for _, currency := range settleCurrencies {
+		a, err := kc.FutureAccount(currency)
+		if err != nil {
+			errs = append(errs, PortfolioError{Account: PortfolioFutures, Currency: currency, Error: err.Error()})
+			continue
+		}
+		if a.AccountEquity.IsZero() {
+			continue
+		}
+		balances = append(balances, PortfolioBalance{Account: PortfolioFutures, Currency: portfolioCurrency(currency),
+			Balance: a.AccountEquity, Available: a.AvailableBalance,
+			Hold:          a.PositionMargin.Add(a.OrderMargin).Add(a.FrozenFunds),
+			UnrealisedPNL: a.UnrealisedPNL})
+	}
+
+	tickers, err := kc.SpotAllTickers()
+	if err != nil {
+		return nil, err
+	}
+	prices := portfolioPrices{}
+	for _, ticker := range tickers.Ticker {
+		prices[ticker.Symbol] = ticker.Last
+	}
+	snapshot := newPortfolioSnapshot(balances, prices)
+	snapshot.Time = tickers.Time
+	snapshot.DebtRatio = debtRatio
+	snapshot.Errors = append(snapshot.Errors, errs...)
+	return snapshot, nil
+}
+
+// newPortfolioSnapshot Value the balances and sum them by currency
+func newPortfolioSnapshot(balances []PortfolioBalance, prices portfolioPrices) *PortfolioSnapshot {
+	s := &PortfolioSnapshot{Currency: PortfolioCurrency, Balances: balances, Unpriced: []string{}, Errors: []PortfolioError{}}
+	currencies := map[string]*PortfolioBalance{}
+	unpriced := map[string]bool{}
+	for i := range s.Balances {
+		b := &s.Balances[i]
+		price, ok := prices.usdt(b.Currency)
+		if !ok && !unpriced[b.Currency] {
+			unpriced[b.Currency] = true
+			s.Unpriced = append(s.Unpriced, b.Currency)
+		}
+		b.Price = price
+		b.Value = b.Balance.Sub(b.Debt).Mul(price)
+		s.Equity = s.Equity.Add(b.Value)
+		s.Debt = s.Debt.Add(b.Debt.Mul(price))
+		s.UnrealisedPNL = s.UnrealisedPNL.Add(b.UnrealisedPNL.Mul(price))
+
+		c, ok := currencies[b.Currency]
+		if !ok {
+			c = &PortfolioBalance{Currency: b.Currency, Price: price}
+			currencies[b.Currency] = c
+		}
+		c.Balance = c.Balance.Add(b.Balance)
+		c.Available = c.Available.Add(b.Available)
+		c.Hold = c.Hold.Add(b.Hold)
+		c.Debt = c.Debt.Add(b.Debt)
+		c.UnrealisedPNL = c.UnrealisedPNL.Add(b.UnrealisedPNL)
+		c.Value = c.Value.Add(b.Value)
+	}
+	for _, c := range currencies {
+		s.Currencies = append(s.Currencies, *c)
+	}
+	sort.Slice(s.Currencies, func(i, j int) bool {
+		return s.Currencies[i].Currency < s.Currencies[j].Currency
+	})
+	sort.Strings(s.Unpriced)
+	return s
+}
+
+// portfolioPrices Last prices by spot symbol
+type portfolioPrices map[string]decimal.Decimal
+
+// usdt Return the price of the currency in USDT: the price of {currency}-USDT, the inverse of the price
+// of USDT-{currency}, or the price of {currency}-BTC in USDT
+func (p portfolioPrices) usdt(currency string) (decimal.Decimal, bool) {
+	if currency == PortfolioCurrency {
+		return decimal.NewFromInt(1), true
+	}
+	if price, ok := p.last(currency + "-" + PortfolioCurrency); ok {
+		return price, true
+	}
+	if price, ok := p.last(PortfolioCurrency + "-" + currency); ok {
+		return decimal.NewFromInt(1).Div(price), true
+	}
+	if btc, ok := p.last("BTC-" + PortfolioCurrency); ok {
+		if price, ok := p.last(currency + "-BTC"); ok {
+			return price.Mul(btc), true
+		}
+	}
+	return decimal.Zero, false
+}
+
+func (p portfolioPrices) last(symbol string) (decimal.Decimal, bool) {
+	price, ok := p[symbol]
+	return price, ok && price.IsPositive()
+}
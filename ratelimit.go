@@ -0,0 +1,121 @@
+package kugo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// SetRateLimit Limit the REST requests of the instance to rate per second, with bursts of up to burst requests.
+// The spot and future endpoints are limited separately, each with rate and burst, like the resource pools of Kucoin.
+// Requests over the limit wait in do until they are allowed
+func SetRateLimit(rate float64, burst int) Option {
+	return func(kc *Kucoin) error {
+		if err := SetSpotRateLimit(rate, burst)(kc); err != nil {
+			return err
+		}
+		return SetFutureRateLimit(rate, burst)(kc)
+	}
+}
+
+// SetSpotRateLimit Limit the REST requests to the spot endpoint to rate per second, with bursts of up to burst requests
+func SetSpotRateLimit(rate float64, burst int) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		if rate <= 0 || burst <= 0 {
+			return errors.New("rate and burst must be positive")
+		}
+		kc.spotLimiter = newRateLimiter(rate, burst)
+		return nil
+	}
+}
+
+// SetFutureRateLimit Limit the REST requests to the future endpoint to rate per second, with bursts of up to burst requests
+func SetFutureRateLimit(rate float64, burst int) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		if rate <= 0 || burst <= 0 {
+			return errors.New("rate and burst must be positive")
+		}
+		kc.futureLimiter = newRateLimiter(rate, burst)
+		return nil
+	}
+}
+
+// limiter Return the rate limiter of the endpoint, nil if it is not limited
+func (kc *Kucoin) limiter(endpoint string) *rateLimiter {
+	if endpoint == kc.spotEndpoint {
+		return kc.spotLimiter
+	}
+	if endpoint == kc.futureEndpoint {
+		return kc.futureLimiter
+	}
+	return nil
+}
+
+// rateLimiter A token bucket. Tokens go negative when requests are reserved ahead of time
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// refill l.mu must be held
+func (l *rateLimiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// delay Return how long to wait until a token is available. l.mu must be held
+func (l *rateLimiter) delay() time.Duration {
+	if l.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// wait Take a token, waiting until it is available
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	l.refill(time.Now())
+	d := l.delay()
+	l.tokens--
+	l.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// ready Wait until a token is available without taking it, or until ctx is done
+func (l *rateLimiter) ready(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		l.refill(time.Now())
+		d := l.delay()
+		l.mu.Unlock()
+		if d == 0 {
+			return nil
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnknownSymbol is returned when the symbol is not listed
+	ErrUnknownSymbol = errors.New("unknown symbol")
+	// ErrTradingDisabled is returned when trading of the symbol is disabled
+	ErrTradingDisabled = errors.New("trading is disabled")
+	// ErrOrderSizeTooSmall is returned when the size or funds of the order is below the minimum
+	ErrOrderSizeTooSmall = errors.New("order size is too small")
+	// ErrOrderSizeTooLarge is returned when the size or funds of the order is above the maximum
+	ErrOrderSizeTooLarge = errors.New("order size is too large")
+	// ErrOrderFundsTooSmall is returned when the value of the order is below the min funds of the symbol
+	ErrOrderFundsTooSmall = errors.New("order funds is too small")
+	// ErrOrderPriceOutOfBand is returned when the price of the order is out of the price limit of the symbol
+	ErrOrderPriceOutOfBand = errors.New("order price is out of the price limit")
+)
+
+// symbolRulesTTL How long the symbols are cached by the rules of an instance
+const symbolRulesTTL = time.Hour
+
+// symbolRulesMinRefresh The minimum interval of the refreshes caused by unknown symbols
+const symbolRulesMinRefresh = time.Minute
+
+// lastPriceTTL How long the last prices of the price limit check are cached
+const lastPriceTTL = 5 * time.Second
+
+// SetOrderNormalization Normalize and validate the orders with the symbol rules of the instance
+// before sending them. See SymbolRules for the steps. The requests are modified in place.
+func SetOrderNormalization(enable bool) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		kc.normalize = enable
+		return nil
+	}
+}
+
+// Rules Return the symbol rules of the instance, which are used by SetOrderNormalization
+func (kc *Kucoin) Rules() *SymbolRules {
+	return kc.rules
+}
+
+// SymbolRules A cached registry of the trading rules of the symbols.
+// It normalizes orders by flooring the size to the increment and rounding the price to the tick
+// away from the market (down for buy orders, up for sell orders), then validates the minimum and
+// maximum size, the min funds and the price limit of the symbol.
+// An unknown symbol refreshes the cache at most once per minute, and the last prices of the
+// price limit are cached for 5 seconds. It is safe for concurrent use.
+type SymbolRules struct {
+	kc  *Kucoin
+	ttl time.Duration
+
+	refresh     sync.Mutex // Held while loading the symbols, so that one load runs at a time
+	mu          sync.Mutex
+	instruments *Instruments
+	spot        map[string]SymbolsData
+	future      map[string]FutureSymbolData
+	spotAt      time.Time
+	futureAt    time.Time
+	prices      map[string]rulesPrice
+	lastPrice   func(symbol string) (decimal.Decimal, error)
+}
+
+type rulesPrice struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+// NewSymbolRules Create a registry which loads the symbols with kc and caches them for ttl
+func NewSymbolRules(kc *Kucoin, ttl time.Duration) *SymbolRules {
+	r := &SymbolRules{kc: kc, ttl: ttl, prices: map[string]rulesPrice{}}
+	r.lastPrice = func(symbol string) (decimal.Decimal, error) {
+		ticker, err := kc.SpotTicker(symbol)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return ticker.Price, nil
+	}
+	return r
+}
+
+// UseInstruments Look up the symbols in the registry instead of loading them.
+// The registry must be started, it is refreshed in the background
+func (r *SymbolRules) UseInstruments(in *Instruments) {
+	r.mu.Lock()
+	r.instruments = in
+	r.mu.Unlock()
+}
+
+// SetLastPrice Set the last price of the spot symbol for the price limit, e.g. from the ticker stream.
+// The price is used for 5 seconds, after which it is fetched with SpotTicker
+func (r *SymbolRules) SetLastPrice(symbol string, price decimal.Decimal) {
+	r.mu.Lock()
+	r.prices[symbol] = rulesPrice{price: price, at: time.Now()}
+	r.mu.Unlock()
+}
+
+// Spot Return the rules of the spot symbol. The cache is refreshed if it is expired or the symbol is not in it
+func (r *SymbolRules) Spot(symbol string) (*SymbolsData, error) {
+	r.mu.Lock()
+	in := r.instruments
+	r.mu.Unlock()
+	if in != nil {
+		data, ok := in.Spot(symbol)
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownSymbol, symbol)
+		}
+		return &data, nil
+	}
+	return lookupSymbol(r, &r.spot, &r.spotAt, symbol, func() ([]SymbolsData, error) {
+		return r.kc.SpotSymbols("")
+	}, func(s *SymbolsData) string { return s.Symbol })
+}
+
+// Future Return the rules of the contract. The cache is refreshed if it is expired or the contract is not in it
+func (r *SymbolRules) Future(symbol string) (*FutureSymbolData, error) {
+	r.mu.Lock()
+	in := r.instruments
+	r.mu.Unlock()
+	if in != nil {
+		data, ok := in.Future(symbol)
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownSymbol, symbol)
+		}
+		return &data, nil
+	}
+	return lookupSymbol(r, &r.future, &r.futureAt, symbol, r.kc.FutureSymbols,
+		func(s *FutureSymbolData) string { return s.Symbol })
+}
+
+// lookupSymbol Return the symbol from the cache. The cache is loaded without holding r.mu if it is
+// expired, or if the symbol is not in it and the cache is older than symbolRulesMinRefresh
+func lookupSymbol[T any](r *SymbolRules, cache *map[string]T, at *time.Time, symbol string,
+	load func() ([]T, error), key func(item *T) string) (*T, error) {
+	get := func() (T, bool, time.Duration) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		data, ok := (*cache)[symbol]
+		return data, ok, time.Since(*at)
+	}
+	minAge := symbolRulesMinRefresh
+	if r.ttl < minAge {
+		minAge = r.ttl
+	}
+
+	data, ok, age := get()
+	if ok && age <= r.ttl {
+		return &data, nil
+	}
+	if ok || age >= minAge {
+		r.refresh.Lock()
+		// The cache may have been loaded while waiting for the lock
+		if _, _, age = get(); age >= minAge {
+			items, err := load()
+			if err != nil {
+				r.refresh.Unlock()
+				return nil, err
+			}
+			loaded := make(map[string]T, len(items))
+			for i := range items {
+				loaded[key(&items[i])] = items[i]
+			}
+			r.mu.Lock()
+			*cache, *at = loaded, time.Now()
+			r.mu.Unlock()
+		}
+		r.refresh.Unlock()
+		data, ok, _ = get()
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownSymbol, symbol)
+	}
+	return &data, nil
+}
+
+// NormalizeSpotOrder Normalize and validate the spot order in place
+func (r *SymbolRules) NormalizeSpotOrder(req *SpotOrdersRequest) error {
+	rules, err := r.Spot(req.Symbol)
+	if err != nil {
+		return err
+	}
+	o := &spotOrderFields{side: req.Side, orderType: req.Type, price: req.Price, size: req.Size, funds: req.Funds}
+	if err = r.normalizeSpot(rules, o); err != nil {
+		return err
+	}
+	req.Price, req.Size, req.Funds = o.price, o.size, o.funds
+	return nil
+}
+
+// NormalizeSpotMarginOrder Normalize and validate the margin order in place
+func (r *SymbolRules) NormalizeSpotMarginOrder(req *SpotMarginOrderRequest) error {
+	rules, err := r.Spot(req.Symbol)
+	if err != nil {
+		return err
+	}
+	o := &spotOrderFields{side: req.Side, orderType: req.Type, price: req.Price, size: req.Size, funds: req.Funds}
+	if err = r.normalizeSpot(rules, o); err != nil {
+		return err
+	}
+	req.Price, req.Size, req.Funds = o.price, o.size, o.funds
+	return nil
+}
+
+// NormalizeFutureOrder Normalize and validate the future order in place.
+// The size is floored to the lot size and the price is rounded to the tick size
+func (r *SymbolRules) NormalizeFutureOrder(req *FutureOrderRequest) error {
+	rules, err := r.Future(req.Symbol)
+	if err != nil {
+		return err
+	}
+	return NormalizeFutureOrder(rules, req)
+}
+
+// spotOrderFields The fields of the spot and margin orders checked by the rules
+type spotOrderFields struct {
+	side      Side
+	orderType OrderType
+	price     decimal.Decimal
+	size      decimal.Decimal
+	funds     string
+}
+
+func (r *SymbolRules) normalizeSpot(rules *SymbolsData, o *spotOrderFields) error {
+	var lastPrice decimal.Decimal
+	if o.orderType != OrderTypeMarket && rules.PriceLimitRate.IsPositive() {
+		var err error
+		if lastPrice, err = r.price(rules.Symbol); err != nil {
+			return err
+		}
+	}
+	return normalizeSpot(rules, o, lastPrice)
+}
+
+// price Return the cached last price of the symbol, or fetch it if it is older than lastPriceTTL
+func (r *SymbolRules) price(symbol string) (decimal.Decimal, error) {
+	r.mu.Lock()
+	p, ok := r.prices[symbol]
+	r.mu.Unlock()
+	if ok && time.Since(p.at) <= lastPriceTTL {
+		return p.price, nil
+	}
+	price, err := r.lastPrice(symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	r.SetLastPrice(symbol, price)
+	return price, nil
+}
+
+// NormalizeSpotOrder Normalize and validate the spot order in place with the rules.
+// The price limit is checked if lastPrice is positive
+func NormalizeSpotOrder(rules *SymbolsData, req *SpotOrdersRequest, lastPrice decimal.Decimal) error {
+	o := &spotOrderFields{side: req.Side, orderType: req.Type, price: req.Price, size: req.Size, funds: req.Funds}
+	if err := normalizeSpot(rules, o, lastPrice); err != nil {
+		return err
+	}
+	req.Price, req.Size, req.Funds = o.price, o.size, o.funds
+	return nil
+}
+
+func normalizeSpot(rules *SymbolsData, o *spotOrderFields, lastPrice decimal.Decimal) error {
+	if !rules.EnableTrading {
+		return fmt.Errorf("%w: %s", ErrTradingDisabled, rules.Symbol)
+	}
+
+	if !o.size.IsZero() {
+		o.size = floorStep(o.size, rules.BaseIncrement)
+		if o.size.LessThan(rules.BaseMinSize) || !o.size.IsPositive() {
+			return fmt.Errorf("%w: size %s, min %s", ErrOrderSizeTooSmall, o.size, rules.BaseMinSize)
+		}
+		if rules.BaseMaxSize.IsPositive() && o.size.GreaterThan(rules.BaseMaxSize) {
+			return fmt.Errorf("%w: size %s, max %s", ErrOrderSizeTooLarge, o.size, rules.BaseMaxSize)
+		}
+	}
+	if o.funds != "" {
+		funds, err := decimal.NewFromString(o.funds)
+		if err != nil {
+			return fmt.Errorf("invalid funds %s: %v", o.funds, err)
+		}
+		funds = floorStep(funds, rules.QuoteIncrement)
+		if funds.LessThan(rules.QuoteMinSize) || !funds.IsPositive() {
+			return fmt.Errorf("%w: funds %s, min %s", ErrOrderSizeTooSmall, funds, rules.QuoteMinSize)
+		}
+		if rules.QuoteMaxSize.IsPositive() && funds.GreaterThan(rules.QuoteMaxSize) {
+			return fmt.Errorf("%w: funds %s, max %s", ErrOrderSizeTooLarge, funds, rules.QuoteMaxSize)
+		}
+		o.funds = funds.String()
+	}
+	if o.orderType == OrderTypeMarket {
+		return nil
+	}
+
+	o.price = roundPrice(o.price, rules.PriceIncrement, o.side)
+	if !o.price.IsPositive() {
+		return fmt.Errorf("invalid price %s", o.price)
+	}
+	if funds := o.price.Mul(o.size); rules.MinFunds.IsPositive() && funds.LessThan(rules.MinFunds) {
+		return fmt.Errorf("%w: funds %s, min %s", ErrOrderFundsTooSmall, funds, rules.MinFunds)
+	}
+	if lastPrice.IsPositive() && rules.PriceLimitRate.IsPositive() {
+		one := decimal.NewFromInt(1)
+		if o.side == SideBuy {
+			if limit := lastPrice.Mul(one.Add(rules.PriceLimitRate)); o.price.GreaterThan(limit) {
+				return fmt.Errorf("%w: price %s, max %s", ErrOrderPriceOutOfBand, o.price, limit)
+			}
+		} else if limit := lastPrice.Mul(one.Sub(rules.PriceLimitRate)); o.price.LessThan(limit) {
+			return fmt.Errorf("%w: price %s, min %s", ErrOrderPriceOutOfBand, o.price, limit)
+		}
+	}
+	return nil
+}
+
+// NormalizeFutureOrder Normalize and validate the future order in place with the rules of the contract
+func NormalizeFutureOrder(rules *FutureSymbolData, req *FutureOrderRequest) error {
+	if rules.Status != "" && rules.Status != "Open" {
+		return fmt.Errorf("%w: %s is %s", ErrTradingDisabled, rules.Symbol, rules.Status)
+	}
+	if !req.CloseOrder {
+		size := floorStep(decimal.NewFromInt(int64(req.Size)), rules.LotSize)
+		req.Size = int(size.IntPart())
+		if req.Size <= 0 {
+			return fmt.Errorf("%w: size %d, lot size %s", ErrOrderSizeTooSmall, req.Size, rules.LotSize)
+		}
+		if rules.MaxOrderQty.IsPositive() && size.GreaterThan(rules.MaxOrderQty) {
+			return fmt.Errorf("%w: size %d, max %s", ErrOrderSizeTooLarge, req.Size, rules.MaxOrderQty)
+		}
+	}
+	if req.Type == OrderTypeMarket {
+		return nil
+	}
+	req.Price = roundPrice(req.Price, rules.TickSize, req.Side)
+	if !req.Price.IsPositive() {
+		return fmt.Errorf("invalid price %s", req.Price)
+	}
+	if rules.MaxPrice.IsPositive() && req.Price.GreaterThan(rules.MaxPrice) {
+		return fmt.Errorf("%w: price %s, max %s", ErrOrderPriceOutOfBand, req.Price, rules.MaxPrice)
+	}
+	return nil
+}
+
+// floorStep Floor v to a multiple of step
+func floorStep(v, step decimal.Decimal) decimal.Decimal {
+	if !step.IsPositive() {
+		return v
+	}
+	return v.Div(step).Floor().Mul(step)
+}
+
+// roundPrice Round the price to a multiple of tick away from the market
+func roundPrice(price, tick decimal.Decimal, side Side) decimal.Decimal {
+	if !tick.IsPositive() {
+		return price
+	}
+	if side == SideSell {
+		return price.Div(tick).Ceil().Mul(tick)
+	}
+	return price.Div(tick).Floor().Mul(tick)
+}
@@ -10,6 +10,17 @@ import (
 
 // SpotOrder POST /api/v1/orders
 func (kc *Kucoin) SpotOrder(req *SpotOrdersRequest) (*SpotOrderData, error) {
+	if err := kc.checkSpotStatus(false); err != nil {
+		return nil, err
+	}
+	if kc.normalize {
+		if err := kc.rules.NormalizeSpotOrder(req); err != nil {
+			return nil, err
+		}
+	}
+	if err := kc.fillClientOid(&req.ClientOid); err != nil {
+		return nil, err
+	}
 	uri := UriSpotOrders
 	p, err := json.Marshal(req)
 	if err != nil {
@@ -28,11 +39,23 @@ func (kc *Kucoin) SpotOrder(req *SpotOrdersRequest) (*SpotOrderData, error) {
 	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
 		return nil, errors.New(respStruct.Msg)
 	}
+	respStruct.Data.ClientOid = req.ClientOid
 	return &respStruct.Data, nil
 }
 
 // SpotMarginOrder POST /api/v1/margin/order
 func (kc *Kucoin) SpotMarginOrder(req *SpotMarginOrderRequest) (*SpotMarginOrderData, error) {
+	if err := kc.checkSpotStatus(false); err != nil {
+		return nil, err
+	}
+	if kc.normalize {
+		if err := kc.rules.NormalizeSpotMarginOrder(req); err != nil {
+			return nil, err
+		}
+	}
+	if err := kc.fillClientOid(&req.ClientOid); err != nil {
+		return nil, err
+	}
 	uri := UriSpotMarginOrder
 	p, err := json.Marshal(req)
 	if err != nil {
@@ -51,6 +74,7 @@ func (kc *Kucoin) SpotMarginOrder(req *SpotMarginOrderRequest) (*SpotMarginOrder
 	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
 		return nil, errors.New(respStruct.Msg)
 	}
+	respStruct.Data.ClientOid = req.ClientOid
 	return &respStruct.Data, nil
 }
 
@@ -99,6 +123,9 @@ func (kc *Kucoin) SpotOrderFills(req *SpotOrderFillsRequest, currentPage, pageSi
 
 // SpotOrderCancel DELETE /api/v1/orders/{orderId}
 func (kc *Kucoin) SpotOrderCancel(orderId string) (*SpotOrderCancelData, error) {
+	if err := kc.checkSpotStatus(true); err != nil {
+		return nil, err
+	}
 	uri := fmt.Sprintf(UriSpotOrderCancel, orderId)
 	resp, err := kc.do(kc.spotEndpoint, http.MethodDelete, uri, nil)
 	if err != nil {
@@ -175,3 +202,26 @@ func (kc *Kucoin) SpotOrderOne(orderId string) (*SpotOrderOneData, error) {
 	}
 	return &respStruct.Data, nil
 }
+
+// SpotOrderByClientOid GET /api/v1/order/client-order/{clientOid}
+// ErrOrderNotFound is returned if there is no order of the clientOid
+func (kc *Kucoin) SpotOrderByClientOid(clientOid string) (*SpotOrderOneData, error) {
+	uri := fmt.Sprintf(UriSpotOrderByClient, clientOid)
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SpotOrderClientOidResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		// An unknown clientOid is reported with an error
+		return nil, orderNotFoundError(clientOid, respStruct.Msg)
+	}
+	if respStruct.Data == nil {
+		return nil, fmt.Errorf("%w: clientOid %s", ErrOrderNotFound, clientOid)
+	}
+	return respStruct.Data, nil
+}
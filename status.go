@@ -0,0 +1,118 @@
+package kugo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrServiceMaintenance is returned by trading methods while the service status is close.
+	ErrServiceMaintenance = errors.New("kucoin service is under maintenance")
+	// ErrServiceCancelOnly is returned by order placing methods while the service status is cancelonly.
+	ErrServiceCancelOnly = errors.New("kucoin service only accepts cancellations")
+)
+
+// SetServiceStatusWatcher Poll the spot and future service status every interval in the background.
+// While a service is under maintenance, its private trading methods return ErrServiceMaintenance
+// (or ErrServiceCancelOnly for new orders) without sending any request, until the status returns to open.
+// It only takes effect when passed to NewKucoin. Call Close to stop the watcher
+func SetServiceStatusWatcher(interval time.Duration) Option {
+	return func(kc *Kucoin) error {
+		if kc == nil {
+			return errors.New("instance is nil")
+		}
+		if interval <= 0 {
+			return errors.New("interval must be positive")
+		}
+		kc.statusInterval = interval
+		return nil
+	}
+}
+
+// Close Stop the background goroutines of the instance
+func (kc *Kucoin) Close() {
+	kc.statusMu.Lock()
+	defer kc.statusMu.Unlock()
+	if kc.statusStop != nil {
+		close(kc.statusStop)
+		kc.statusStop = nil
+	}
+}
+
+// watchServiceStatus Start the service status watcher if SetServiceStatusWatcher is set
+func (kc *Kucoin) watchServiceStatus() {
+	if kc.statusInterval <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	kc.statusStop = stop
+
+	go func() {
+		ticker := time.NewTicker(kc.statusInterval)
+		defer ticker.Stop()
+		for {
+			kc.refreshServiceStatus()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// refreshServiceStatus Update the cached service status. The previous status is kept if the request fails
+func (kc *Kucoin) refreshServiceStatus() {
+	spot, err := kc.SpotServiceStatus()
+	if err != nil {
+		kc.statusLog("spot", err)
+	}
+	future, err := kc.FutureServiceStatus()
+	if err != nil {
+		kc.statusLog("future", err)
+	}
+
+	kc.statusMu.Lock()
+	defer kc.statusMu.Unlock()
+	if spot != nil {
+		kc.spotStatus = spot.Status
+	}
+	if future != nil {
+		kc.futureStatus = future.Status
+	}
+}
+
+func (kc *Kucoin) statusLog(market string, err error) {
+	if kc.debug {
+		kc.respLog(fmt.Sprintf("info:service_status\tmarket:%s\terror:%v", market, err))
+	}
+}
+
+// checkSpotStatus Return an error if the spot service does not accept the trading request.
+// cancel is true for cancellation requests, which are still accepted in cancelonly status
+func (kc *Kucoin) checkSpotStatus(cancel bool) error {
+	kc.statusMu.RLock()
+	defer kc.statusMu.RUnlock()
+	return serviceStatusError(kc.spotStatus, cancel)
+}
+
+// checkFutureStatus Return an error if the future service does not accept the trading request.
+// cancel is true for cancellation requests, which are still accepted in cancelonly status
+func (kc *Kucoin) checkFutureStatus(cancel bool) error {
+	kc.statusMu.RLock()
+	defer kc.statusMu.RUnlock()
+	return serviceStatusError(kc.futureStatus, cancel)
+}
+
+func serviceStatusError(status string, cancel bool) error {
+	switch status {
+	case ServiceStatusClose:
+		return ErrServiceMaintenance
+	case ServiceStatusCancelOnly:
+		if !cancel {
+			return ErrServiceCancelOnly
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package kugo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// SubUserList GET /api/v2/sub/user
+func (kc *Kucoin) SubUserList(currentPage, pageSize int) (*SubUserListData, error) {
+	uri := UriSubUserList
+	p := map[string]string{}
+	p["currentPage"] = strconv.Itoa(currentPage)
+	p["pageSize"] = strconv.Itoa(pageSize)
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubUserListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubAccountOne GET /api/v1/sub-accounts/{subUserId}
+func (kc *Kucoin) SubAccountOne(subUserId string) (*SubAccountData, error) {
+	uri := fmt.Sprintf(UriSubAccountOne, subUserId)
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubAccountResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubAccountList GET /api/v1/sub-accounts
+func (kc *Kucoin) SubAccountList() ([]SubAccountData, error) {
+	uri := UriSubAccountList
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubAccountListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// SubAccountListV2 GET /api/v2/sub-accounts
+func (kc *Kucoin) SubAccountListV2(currentPage, pageSize int) (*SubAccountListV2Data, error) {
+	uri := UriSubAccountListV2
+	p := map[string]string{}
+	p["currentPage"] = strconv.Itoa(currentPage)
+	p["pageSize"] = strconv.Itoa(pageSize)
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubAccountListV2Response{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubApiKeyList GET /api/v1/sub/api-key
+func (kc *Kucoin) SubApiKeyList(subName, apiKey string) ([]SubApiKeyData, error) {
+	uri := UriSubApiKey
+	p := map[string]string{}
+	p["subName"] = subName
+	if len(apiKey) != 0 {
+		p["apiKey"] = apiKey
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodGet, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubApiKeyListResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return respStruct.Data, nil
+}
+
+// SubApiKeyCreate POST /api/v1/sub/api-key
+func (kc *Kucoin) SubApiKeyCreate(req *SubApiKeyCreateRequest) (*SubApiKeyData, error) {
+	uri := UriSubApiKey
+	p, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodPost, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubApiKeyCreateResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubApiKeyUpdate POST /api/v1/sub/api-key/update
+func (kc *Kucoin) SubApiKeyUpdate(req *SubApiKeyUpdateRequest) (*SubApiKeyUpdateData, error) {
+	uri := UriSubApiKeyUpdate
+	p, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodPost, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubApiKeyUpdateResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubApiKeyDelete DELETE /api/v1/sub/api-key
+func (kc *Kucoin) SubApiKeyDelete(subName, apiKey, passphrase string) (*SubApiKeyDeleteData, error) {
+	uri := UriSubApiKey
+	p := map[string]string{}
+	p["subName"] = subName
+	p["apiKey"] = apiKey
+	p["passphrase"] = passphrase
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodDelete, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubApiKeyDeleteResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
+
+// SubTransfer POST /api/v2/accounts/sub-transfer
+// Transfer funds between the master account and a sub-account.
+// Direction OUT moves funds from master to sub, IN moves funds from sub to master.
+func (kc *Kucoin) SubTransfer(req *SubTransferRequest) (*SubTransferData, error) {
+	uri := UriSubAccountTransfer
+	p, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := kc.do(kc.spotEndpoint, http.MethodPost, uri, p)
+	if err != nil {
+		return nil, err
+	}
+
+	respStruct := &SubTransferResponse{}
+	if err = json.Unmarshal(resp.Body(), &respStruct); err != nil {
+		return nil, err
+	}
+	if respStruct.Code != "200000" && respStruct.Code != "200" || len(respStruct.Msg) != 0 {
+		return nil, errors.New(respStruct.Msg)
+	}
+	return &respStruct.Data, nil
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func tick(sec float64, price, size int64) kugo.Tick {
+	return kugo.Tick{Time: int64(sec * float64(time.Second)), Price: decimal.NewFromInt(price), Size: decimal.NewFromInt(size)}
+}
+
+func TestCandleAggregatorTimeBars(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+
+	var mu sync.Mutex
+	var bars []*kugo.Bar
+	a, err := kugo.NewSpotCandleAggregator(s.kucoin(t), ws, "BTC-USDT", kugo.TimeBars(5*time.Second),
+		kugo.SetCandleLateness(3*time.Second),
+		kugo.SetCandleHandler(func(bar *kugo.Bar) {
+			mu.Lock()
+			bars = append(bars, bar)
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Add(tick(1, 100, 1))
+	a.Add(tick(2, 103, 2))
+	a.Add(tick(3, 99, 1))
+	a.Add(tick(6, 101, 1)) // Closes [0s, 5s)
+	a.Add(tick(4, 98, 1))  // Late within the lateness, amends [0s, 5s)
+	a.Add(tick(11, 102, 1))
+	a.Add(tick(7, 97, 1)) // Too late
+	if n := a.Dropped(); n != 1 {
+		t.Fatalf("want 1 dropped trade, got %d", n)
+	}
+
+	closed := a.Bars()
+	if len(closed) != 2 {
+		t.Fatalf("unexpected bars %+v", closed)
+	}
+	first := closed[0]
+	if !first.Open.Equal(decimal.NewFromInt(100)) || !first.High.Equal(decimal.NewFromInt(103)) ||
+		!first.Low.Equal(decimal.NewFromInt(98)) || !first.Close.Equal(decimal.NewFromInt(98)) ||
+		!first.Volume.Equal(decimal.NewFromInt(5)) || first.Trades != 4 || !first.Amended {
+		t.Fatalf("unexpected bar %+v", first)
+	}
+	if current, ok := a.Current(); !ok || current.Start != int64(10*time.Second) {
+		t.Fatalf("unexpected open bar %+v", current)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bars) != 3 || bars[0].Amended || !bars[1].Amended {
+		t.Fatalf("unexpected emitted bars %+v", bars)
+	}
+}
+
+func TestCandleAggregatorThresholdBars(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+
+	a, err := kugo.NewSpotCandleAggregator(s.kucoin(t), ws, "BTC-USDT", kugo.DollarBars(decimal.NewFromInt(500)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Add(tick(1, 100, 2))
+	a.Add(tick(2, 100, 3)) // Reaches 500
+	a.Add(tick(3, 100, 1))
+	closed := a.Bars()
+	if len(closed) != 1 || !closed[0].Turnover.Equal(decimal.NewFromInt(500)) || closed[0].End != int64(2*time.Second) {
+		t.Fatalf("unexpected bars %+v", closed)
+	}
+	if _, err = kugo.NewSpotCandleAggregator(s.kucoin(t), ws, "BTC-USDT", kugo.VolumeBars(decimal.Zero)); err == nil {
+		t.Fatal("want an error for a zero threshold")
+	}
+	if err = a.Bootstrap(time.Now().Add(-time.Hour), time.Now()); err == nil {
+		t.Fatal("want an error for bootstrapping dollar bars")
+	}
+}
+
+func TestCandleAggregatorStream(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	// 1min klines of [0, 3min), the newest first
+	s.mux.HandleFunc(kugo.UriSpotKlines, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "1min" {
+			t.Errorf("unexpected kline type %s", r.URL.Query().Get("type"))
+		}
+		w.Write([]byte(`{"code":"200000","data":[` +
+			`["120","12","13","14","11","3","39"],` +
+			`["60","11","12","12","10","2","22"],` +
+			`["0","10","11","11","9","1","10"]]}`))
+	})
+
+	kc := s.kucoin(t)
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	a, err := kugo.NewSpotCandleAggregator(kc, ws, "BTC-USDT", kugo.TimeBars(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = a.Bootstrap(time.Unix(0, 0), time.Unix(240, 0)); err != nil {
+		t.Fatal(err)
+	}
+	bars := a.Bars()
+	if len(bars) != 2 || !bars[0].Volume.Equal(decimal.NewFromInt(3)) || !bars[0].High.Equal(decimal.NewFromInt(12)) ||
+		!bars[0].Close.Equal(decimal.NewFromInt(12)) || !bars[1].Open.Equal(decimal.NewFromInt(12)) {
+		t.Fatalf("unexpected bootstrapped bars %+v", bars)
+	}
+
+	if err = a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	s.waitSubscribe(t, "/market/match:BTC-USDT")
+	// A trade before the bootstrapped range is dropped silently, the next one opens a bar
+	s.push("/market/match:BTC-USDT", "trade.l3match", `{"sequence":"1","symbol":"BTC-USDT","side":"buy","price":"15","size":"1","tradeId":"1","time":"200000000000"}`)
+	s.push("/market/match:BTC-USDT", "trade.l3match", `{"sequence":"2","symbol":"BTC-USDT","side":"buy","price":"16","size":"2","tradeId":"2","time":"250000000000"}`)
+	waitUntil(t, "an open bar", func() bool {
+		bar, ok := a.Current()
+		return ok && bar.Start == int64(240*time.Second) && bar.Trades == 1
+	})
+}
+
+func TestCandleAggregatorWeekBars(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	monday := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	s.mux.HandleFunc(kugo.UriSpotKlines, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"code":"200000","data":[["%d","11","12","12","10","2","22"],["%d","10","11","11","9","1","10"]]}`,
+			monday.Add(week).Unix(), monday.Unix())))
+	})
+
+	if _, err := kugo.NewSpotCandleAggregator(s.kucoin(t), nil, "BTC-USDT", kugo.TimeBars(time.Nanosecond)); err == nil {
+		t.Fatal("want an error of an interval under 1ms")
+	}
+	a, err := kugo.NewSpotCandleAggregator(s.kucoin(t), nil, "BTC-USDT", kugo.TimeBars(week))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Weekly bars start on Monday like the weekly klines
+	if err = a.Bootstrap(monday, monday.Add(2*week)); err != nil {
+		t.Fatal(err)
+	}
+	bars := a.Bars()
+	if len(bars) != 2 || bars[0].Start != monday.UnixNano() || bars[1].Start != monday.Add(week).UnixNano() {
+		t.Fatalf("unexpected bootstrapped bars %+v", bars)
+	}
+	a.Add(kugo.Tick{Time: monday.Add(2*week + 50*time.Hour).UnixNano(), Price: decimal.NewFromInt(13), Size: decimal.NewFromInt(1)})
+	if bar, ok := a.Current(); !ok || bar.Start != monday.Add(2*week).UnixNano() || bar.End != monday.Add(3*week).UnixNano() {
+		t.Fatalf("unexpected open bar %+v", bar)
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAutoClientOid(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var sent kugo.SpotOrdersRequest
+	s.mux.HandleFunc(kugo.UriSpotOrders, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &sent)
+		w.Write([]byte(`{"code":"200000","data":{"orderId":"1"}}`))
+	})
+	kc := s.kucoin(t)
+	if err := kc.Set(kugo.SetAutoClientOid("grid-")); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: kugo.SideBuy, Type: kugo.OrderTypeMarket, Funds: "10"}
+	data, err := kc.SpotOrder(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(data.ClientOid, "grid-") || len(data.ClientOid) != 37 || sent.ClientOid != data.ClientOid {
+		t.Fatalf("unexpected clientOid %q, sent %q", data.ClientOid, sent.ClientOid)
+	}
+
+	req = &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: kugo.SideBuy, Type: kugo.OrderTypeMarket, Funds: "10", ClientOid: "mine"}
+	if data, err = kc.SpotOrder(req); err != nil || data.ClientOid != "mine" {
+		t.Fatalf("the clientOid is replaced: %+v %v", data, err)
+	}
+
+	if err = kc.Set(kugo.SetAutoClientOid("a-very-long-prefix")); err == nil {
+		t.Fatal("want an error of a long prefix")
+	}
+	n := 0
+	if err = kc.Set(kugo.SetClientOidGenerator("s1-", func() string { n++; return strings.Repeat("x", n) })); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = kc.SpotOrder(&kugo.SpotOrdersRequest{Symbol: "BTC-USDT"}); err != nil || data.ClientOid != "s1-x" {
+		t.Fatalf("unexpected clientOid %+v %v", data, err)
+	}
+}
+
+func TestPlaceOrderIdempotent(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var mu sync.Mutex
+	placed := map[string]bool{}
+	requests := 0
+	s.mux.HandleFunc(kugo.UriFutureOrders, func(w http.ResponseWriter, r *http.Request) {
+		var req kugo.FutureOrderRequest
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &req)
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		if req.Symbol == "XBTUSDTM" {
+			mu.Lock()
+			placed[req.ClientOid] = true
+			mu.Unlock()
+		}
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte(`{"code":"200000","data":{"orderId":"1"}}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureOrderByClient, func(w http.ResponseWriter, r *http.Request) {
+		clientOid := r.URL.Query().Get("clientOid")
+		mu.Lock()
+		ok := placed[clientOid]
+		mu.Unlock()
+		if !ok {
+			w.Write([]byte(`{"code":"200000","data":null}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200000","data":{"id":"1","symbol":"XBTUSDTM","clientOid":"` + clientOid + `"}}`))
+	})
+	kc, err := kugo.NewKucoin(
+		kugo.SetSpotEndpoint(s.URL),
+		kugo.SetFutureEndpoint(s.URL),
+		kugo.SetClient(&http.Client{Timeout: 100 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &kugo.FutureOrderRequest{Symbol: "XBTUSDTM", Side: kugo.SideBuy, Type: kugo.OrderTypeLimit, Price: decimal.NewFromInt(20000), Size: 1}
+	order, err := kc.PlaceOrderIdempotent(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.OrderId != "1" || !order.Resolved || order.ClientOid == "" || order.ClientOid != req.ClientOid {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	// The order is not found within the lookup window of ctx
+	req = &kugo.FutureOrderRequest{Symbol: "ETHUSDTM", Side: kugo.SideBuy, Type: kugo.OrderTypeLimit, Price: decimal.NewFromInt(2000), Size: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 1200*time.Millisecond)
+	defer cancel()
+	if _, err = kc.PlaceOrderIdempotent(ctx, req); !errors.Is(err, kugo.ErrOrderNotPlaced) {
+		t.Fatalf("want ErrOrderNotPlaced, got %v", err)
+	}
+
+	// The order is accepted late, so placing the same request again returns it instead of placing it twice
+	mu.Lock()
+	placed[req.ClientOid] = true
+	before := requests
+	mu.Unlock()
+	order, err = kc.PlaceOrderIdempotent(context.Background(), req)
+	if err != nil || !order.Resolved || order.ClientOid != req.ClientOid {
+		t.Fatalf("unexpected order %+v %v", order, err)
+	}
+	mu.Lock()
+	if requests != before {
+		t.Fatalf("the order is placed again")
+	}
+	mu.Unlock()
+	if _, err = kc.PlaceOrderIdempotent(context.Background(), kugo.SpotOrdersRequest{}); err == nil {
+		t.Fatal("want an error of an unsupported request")
+	}
+}
+
+func TestPlaceSpotOrderIdempotent(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	s.mux.HandleFunc(kugo.UriSpotOrders, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte(`{"code":"200000","data":{"orderId":"1"}}`))
+	})
+	// The spot endpoint reports an unknown clientOid with an error
+	s.mux.HandleFunc(strings.TrimSuffix(kugo.UriSpotOrderByClient, "%s"), func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"400100","msg":"order not exist."}`))
+	})
+	kc, err := kugo.NewKucoin(
+		kugo.SetSpotEndpoint(s.URL),
+		kugo.SetClient(&http.Client{Timeout: 100 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = kc.SpotOrderByClientOid("unknown"); !errors.Is(err, kugo.ErrOrderNotFound) {
+		t.Fatalf("want ErrOrderNotFound, got %v", err)
+	}
+	req := &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: kugo.SideBuy, Type: kugo.OrderTypeLimit, Price: decimal.NewFromInt(20000), Size: decimal.NewFromInt(1)}
+	window, cancelWindow := context.WithTimeout(context.Background(), 1200*time.Millisecond)
+	defer cancelWindow()
+	if _, err = kc.PlaceOrderIdempotent(window, req); !errors.Is(err, kugo.ErrOrderNotPlaced) {
+		t.Fatalf("want ErrOrderNotPlaced, got %v", err)
+	}
+
+	// The lookups stop when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	req.ClientOid = ""
+	start := time.Now()
+	if _, err = kc.PlaceOrderIdempotent(ctx, req); !errors.Is(err, kugo.ErrOrderOutcomeUnknown) {
+		t.Fatalf("want ErrOrderOutcomeUnknown, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("the lookups took %s after the context was done", elapsed)
+	}
+}
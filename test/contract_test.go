@@ -0,0 +1,61 @@
+package test
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"testing"
+)
+
+func TestContractConversion(t *testing.T) {
+	price := decimal.NewFromInt(20000)
+	linear := &kugo.FutureSymbolData{Symbol: "XBTUSDTM", Multiplier: decimal.RequireFromString("0.001"), LotSize: decimal.NewFromInt(1)}
+	inverse := &kugo.FutureSymbolData{Symbol: "XBTUSDM", Multiplier: decimal.NewFromInt(-1), LotSize: decimal.NewFromInt(1), IsInverse: true}
+
+	if qty, _ := linear.BaseQty(decimal.NewFromInt(50), price); !qty.Equal(decimal.RequireFromString("0.05")) {
+		t.Fatalf("unexpected base qty %s", qty)
+	}
+	if notional, _ := linear.Notional(decimal.NewFromInt(50), price); !notional.Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("unexpected notional %s", notional)
+	}
+	if n, _ := linear.ContractsForBase(decimal.RequireFromString("0.0509"), price); n != 50 {
+		t.Fatalf("unexpected contracts %d", n)
+	}
+	if n, _ := linear.ContractsForNotional(decimal.NewFromInt(1019), price); n != 50 {
+		t.Fatalf("unexpected contracts %d", n)
+	}
+
+	if qty, _ := inverse.BaseQty(decimal.NewFromInt(1000), price); !qty.Equal(decimal.RequireFromString("0.05")) {
+		t.Fatalf("unexpected base qty %s", qty)
+	}
+	if notional, _ := inverse.Notional(decimal.NewFromInt(1000), price); !notional.Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("unexpected notional %s", notional)
+	}
+	if n, _ := inverse.ContractsForBase(decimal.RequireFromString("0.05"), price); n != 1000 {
+		t.Fatalf("unexpected contracts %d", n)
+	}
+
+	quanto := &kugo.FutureSymbolData{Symbol: "ETHUSDM", Multiplier: decimal.RequireFromString("0.000001"), IsQuanto: true}
+	if _, err := quanto.Notional(decimal.NewFromInt(1), price); !errors.Is(err, kugo.ErrQuantoContract) {
+		t.Fatalf("want ErrQuantoContract, got %v", err)
+	}
+}
+
+func TestFutureOrderBuilder(t *testing.T) {
+	linear := &kugo.FutureSymbolData{Symbol: "XBTUSDTM", Multiplier: decimal.RequireFromString("0.001"), LotSize: decimal.NewFromInt(1)}
+
+	req, err := kugo.NewFutureOrderBuilder(linear, "buy").Limit(decimal.NewFromInt(20000)).BaseQty(decimal.RequireFromString("0.25")).Leverage(decimal.NewFromInt(5)).Build()
+	if err != nil || req.Size != 250 || req.Type != "limit" || req.Symbol != "XBTUSDTM" {
+		t.Fatalf("unexpected request %+v %v", req, err)
+	}
+	req, err = kugo.NewFutureOrderBuilder(linear, "sell").Market(decimal.NewFromInt(20000)).Notional(decimal.NewFromInt(2000)).ReduceOnly().Build()
+	if err != nil || req.Size != 100 || !req.Price.IsZero() || !req.ReduceOnly {
+		t.Fatalf("unexpected request %+v %v", req, err)
+	}
+	if _, err = kugo.NewFutureOrderBuilder(linear, "buy").Limit(decimal.NewFromInt(20000)).Notional(decimal.NewFromInt(10)).Build(); !errors.Is(err, kugo.ErrOrderSizeTooSmall) {
+		t.Fatalf("want ErrOrderSizeTooSmall, got %v", err)
+	}
+	if _, err = kugo.NewFutureOrderBuilder(linear, "buy").Contracts(1).Build(); err == nil {
+		t.Fatal("want an error without order type")
+	}
+}
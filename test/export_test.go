@@ -0,0 +1,196 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFillsServer Serve the fills in a time range, newest first.
+// The spot and future fills share the path, so it serves both endpoints
+func newFillsServer(fills *[]kugo.SpotOrderFillsItem, mu *sync.Mutex) *fakeServer {
+	s := newFakeServer()
+	s.mux.HandleFunc(kugo.UriSpotOrderFills, func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.ParseInt(r.URL.Query().Get("startAt"), 10, 64)
+		endAt, _ := strconv.ParseInt(r.URL.Query().Get("endAt"), 10, 64)
+		mu.Lock()
+		var items []kugo.SpotOrderFillsItem
+		for i := len(*fills) - 1; i >= 0; i-- {
+			if f := (*fills)[i]; f.CreatedAt >= startAt && f.CreatedAt <= endAt {
+				items = append(items, f)
+			}
+		}
+		mu.Unlock()
+		b, _ := json.Marshal(map[string]interface{}{"code": "200000", "data": map[string]interface{}{
+			"currentPage": 1, "pageSize": 500, "totalNum": len(items), "totalPage": 1, "items": items,
+		}})
+		w.Write(b)
+	})
+	return s
+}
+
+func TestExportSpotFills(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	fill := func(id string, at int64) kugo.SpotOrderFillsItem {
+		return kugo.SpotOrderFillsItem{TradeId: id, OrderId: "o" + id, Symbol: "BTC-USDT", Side: "buy", CreatedAt: at,
+			Price: decimal.RequireFromString("16500.1"), Size: decimal.RequireFromString("0.00012345"),
+			Fee: decimal.RequireFromString("0.002"), FeeCurrency: "USDT"}
+	}
+	var mu sync.Mutex
+	fills := []kugo.SpotOrderFillsItem{fill("1", start+1000), fill("2", start+2000), fill("3", start+2000)}
+	s := newFillsServer(&fills, &mu)
+	defer s.Close()
+	kc := s.kucoin(t)
+
+	cursor := filepath.Join(t.TempDir(), "fills.cursor")
+	out := &bytes.Buffer{}
+	e, err := kugo.NewExporter(out, kugo.ExportCSV, kugo.SetExportCursor(cursor), kugo.SetExportLocation(time.FixedZone("UTC+8", 8*3600)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &kugo.SpotOrderFillsRequest{StartAt: start, EndAt: start + 10*24*3600*1000}
+	if n, err := kc.ExportSpotFills(context.Background(), e, req); err != nil || n != 3 {
+		t.Fatalf("exported %d fills, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || lines[0] != strings.Join(kugo.SpotFillColumns, ",") {
+		t.Fatalf("unexpected csv %q", out.String())
+	}
+	if want := "2023-01-01T08:00:01.000+08:00,1,o1,BTC-USDT,buy,,,,16500.1,0.00012345,0,0.002,0,USDT"; lines[1] != want {
+		t.Fatalf("unexpected row %q, want %q", lines[1], want)
+	}
+
+	// A new fill at the time of the cursor and a later one, only they are exported
+	mu.Lock()
+	fills = append(fills, fill("4", start+2000), fill("5", start+3000))
+	mu.Unlock()
+	out.Reset()
+	if e, err = kugo.NewExporter(out, kugo.ExportCSV, kugo.SetExportCursor(cursor), kugo.SetExportAppend(true)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := kc.ExportSpotFills(context.Background(), e, req); err != nil || n != 2 {
+		t.Fatalf("exported %d fills, %v", n, err)
+	}
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "2023-01-01T00:00:02.000Z,4,") || !strings.HasPrefix(lines[1], "2023-01-01T00:00:03.000Z,5,") {
+		t.Fatalf("unexpected resumed csv %q", out.String())
+	}
+
+	// A new file gets the header even if the cursor exists, a file with records does not
+	mu.Lock()
+	fills = append(fills, fill("6", start+4000))
+	mu.Unlock()
+	path := filepath.Join(t.TempDir(), "fills.csv")
+	for i, want := range []int{2, 3} {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, err = kugo.NewExporter(f, kugo.ExportCSV, kugo.SetExportCursor(cursor)); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			mu.Lock()
+			fills = append(fills, fill("7", start+5000))
+			mu.Unlock()
+		}
+		if _, err = kc.ExportSpotFills(context.Background(), e, req); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		b, _ := os.ReadFile(path)
+		lines = strings.Split(strings.TrimSpace(string(b)), "\n")
+		if len(lines) != want || lines[0] != strings.Join(kugo.SpotFillColumns, ",") {
+			t.Fatalf("unexpected csv file %q", b)
+		}
+	}
+}
+
+func TestExportCursorProgress(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	week := int64(7 * 24 * 3600 * 1000)
+	release := make(chan struct{})
+	var once sync.Once
+	s := newFakeServer()
+	defer s.Close()
+	defer once.Do(func() { close(release) })
+	s.mux.HandleFunc(kugo.UriSpotOrderFills, func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.ParseInt(r.URL.Query().Get("startAt"), 10, 64)
+		items := []kugo.SpotOrderFillsItem{{TradeId: "1", CreatedAt: start + 1000}}
+		if startAt > start {
+			// Hold the second window until the progress of the first one is checked
+			<-release
+			items = []kugo.SpotOrderFillsItem{{TradeId: "2", CreatedAt: start + week + 1000}}
+		}
+		b, _ := json.Marshal(map[string]interface{}{"code": "200000", "data": map[string]interface{}{
+			"currentPage": 1, "pageSize": 500, "totalNum": len(items), "totalPage": 1, "items": items,
+		}})
+		w.Write(b)
+	})
+	kc := s.kucoin(t)
+
+	dir := t.TempDir()
+	cursor, path := filepath.Join(dir, "fills.cursor"), filepath.Join(dir, "fills.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	e, err := kugo.NewExporter(f, kugo.ExportCSV, kugo.SetExportCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := kc.ExportSpotFills(context.Background(), e, &kugo.SpotOrderFillsRequest{StartAt: start, EndAt: start + 2*week})
+		done <- result{n, err}
+	}()
+
+	// The first window is flushed and its cursor is saved before the second one is loaded
+	waitUntil(t, "cursor of the first window", func() bool {
+		b, _ := os.ReadFile(cursor)
+		return string(b) == `{"time":`+strconv.FormatInt(start+1000, 10)+`,"ids":["1"]}`
+	})
+	if b, _ := os.ReadFile(path); strings.Count(string(b), "\n") != 2 {
+		t.Fatalf("unexpected csv of the first window %q", b)
+	}
+	once.Do(func() { close(release) })
+	if r := <-done; r.err != nil || r.n != 2 {
+		t.Fatalf("exported %d fills, %v", r.n, r.err)
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	out := &bytes.Buffer{}
+	e, err := kugo.NewExporter(out, kugo.ExportJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger := &kugo.LedgerItem{Id: "1", Currency: "USDT", Amount: decimal.RequireFromString("12.3456789012345678"),
+		BizType: "TRADE_EXCHANGE", Direction: "in", CreatedAt: 1672531200000, Context: `{"orderId":"o1"}`}
+	if err = e.WriteLedger(ledger); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"time":"2023-01-01T00:00:00.000Z","id":"1","currency":"USDT","amount":"12.3456789012345678","fee":"0","balance":"0",` +
+		`"accountType":"","bizType":"TRADE_EXCHANGE","direction":"in","context":"{\"orderId\":\"o1\"}"}` + "\n"
+	if out.String() != want {
+		t.Fatalf("unexpected jsonl %s", out.String())
+	}
+	if err = e.WriteSpotFill(&kugo.SpotOrderFillsItem{}); err == nil {
+		t.Fatal("want an error of another kind of records")
+	}
+}
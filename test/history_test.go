@@ -0,0 +1,70 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSpotOrderFillsHistory(t *testing.T) {
+	const day = int64(24 * time.Hour / time.Millisecond)
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	// The third fill is on the boundary of the first two windows
+	times := []int64{start + day, start + 3*day, start + 7*day, start + 8*day, start + 14*day + 1}
+
+	s := newFakeServer()
+	defer s.Close()
+	var mu sync.Mutex
+	var windows [][2]int64
+	s.mux.HandleFunc(kugo.UriSpotOrderFills, func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.ParseInt(r.URL.Query().Get("startAt"), 10, 64)
+		endAt, _ := strconv.ParseInt(r.URL.Query().Get("endAt"), 10, 64)
+		mu.Lock()
+		windows = append(windows, [2]int64{startAt, endAt})
+		mu.Unlock()
+		// Newest first, both ends inclusive
+		var items []kugo.SpotOrderFillsItem
+		for i := len(times) - 1; i >= 0; i-- {
+			if times[i] >= startAt && times[i] <= endAt {
+				items = append(items, kugo.SpotOrderFillsItem{TradeId: strconv.Itoa(i), CreatedAt: times[i]})
+			}
+		}
+		b, _ := json.Marshal(map[string]interface{}{"code": "200000", "data": map[string]interface{}{
+			"currentPage": 1, "pageSize": 500, "totalNum": len(items), "totalPage": 1, "items": items,
+		}})
+		w.Write(b)
+	})
+	kc := s.kucoin(t)
+
+	history := kc.SpotOrderFillsHistory(context.Background(), &kugo.SpotOrderFillsRequest{StartAt: start, EndAt: start + 15*day})
+	var ids []string
+	for history.Next() {
+		ids = append(ids, history.Item().TradeId)
+	}
+	if err := history.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[0 1 2 3 4]" {
+		t.Fatalf("unexpected fills %v", ids)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(windows) != 3 {
+		t.Fatalf("unexpected windows %v", windows)
+	}
+	for _, w := range windows {
+		if w[1]-w[0] > 7*day {
+			t.Fatalf("window %v is longer than 7 days", w)
+		}
+	}
+
+	if history = kc.SpotOrderFillsHistory(context.Background(), &kugo.SpotOrderFillsRequest{}); history.Next() || history.Err() == nil {
+		t.Fatal("want an error without startAt")
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"errors"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInstruments(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var refreshes int32
+	s.mux.HandleFunc(kugo.UriSpotSymbols, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&refreshes, 1) == 1 {
+			w.Write([]byte(`{"code":"200000","data":[` +
+				`{"symbol":"BTC-USDT","baseCurrency":"BTC","quoteCurrency":"USDT","enableTrading":true},` +
+				`{"symbol":"ETH-USDT","baseCurrency":"ETH","quoteCurrency":"USDT","enableTrading":true},` +
+				`{"symbol":"ETH-BTC","baseCurrency":"ETH","quoteCurrency":"BTC","enableTrading":true}]}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200000","data":[` +
+			`{"symbol":"BTC-USDT","baseCurrency":"BTC","quoteCurrency":"USDT","enableTrading":false},` +
+			`{"symbol":"ETH-USDT","baseCurrency":"ETH","quoteCurrency":"USDT","enableTrading":true},` +
+			`{"symbol":"SOL-USDT","baseCurrency":"SOL","quoteCurrency":"USDT","enableTrading":true}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureSymbols, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[` +
+			`{"symbol":"XBTUSDTM","baseCurrency":"XBT","quoteCurrency":"USDT","settleCurrency":"USDT","status":"Open"},` +
+			`{"symbol":"XBTUSDM","baseCurrency":"XBT","quoteCurrency":"USD","settleCurrency":"XBT","status":"Open"}]}`))
+	})
+
+	var mu sync.Mutex
+	var events []string
+	in, err := kugo.NewInstruments(s.kucoin(t),
+		kugo.SetInstrumentsInterval(20*time.Millisecond),
+		kugo.SetInstrumentsHandler(func(e *kugo.InstrumentEvent) {
+			mu.Lock()
+			events = append(events, e.Type+" "+e.Symbol)
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = in.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	if _, ok := in.Spot("ETH-BTC"); !ok {
+		t.Fatal("ETH-BTC is not found")
+	}
+	if n := len(in.SpotByBase("ETH")); n != 2 {
+		t.Fatalf("want 2 symbols of ETH, got %d", n)
+	}
+	if list := in.FutureBySettle("USDT"); len(list) != 1 || list[0].Symbol != "XBTUSDTM" {
+		t.Fatalf("unexpected contracts settled in USDT %+v", list)
+	}
+	if n := len(in.FutureByBase("XBT")); n != 2 {
+		t.Fatalf("want 2 contracts of XBT, got %d", n)
+	}
+
+	waitUntil(t, "instrument events", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 3
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(events)
+	want := []string{"delisted ETH-BTC", "disabled BTC-USDT", "listed SOL-USDT"}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("unexpected events %v", events)
+		}
+	}
+	if n := len(in.SpotByQuote("USDT")); n != 3 {
+		t.Fatalf("want 3 symbols quoted in USDT, got %d", n)
+	}
+}
+
+func TestSymbolRulesInstruments(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var loads int32
+	s.mux.HandleFunc(kugo.UriSpotSymbols, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&loads, 1)
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"BTC-USDT","baseCurrency":"BTC","quoteCurrency":"USDT","enableTrading":true}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureSymbols, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"XBTUSDTM","settleCurrency":"USDT","status":"Open"}]}`))
+	})
+	kc := s.kucoin(t)
+	in, err := kugo.NewInstruments(kc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = in.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	// The rules look the symbols up in the registry instead of loading them again
+	kc.Rules().UseInstruments(in)
+	if data, err := kc.Rules().Spot("BTC-USDT"); err != nil || data.Symbol != "BTC-USDT" {
+		t.Fatalf("unexpected rules %+v %v", data, err)
+	}
+	if data, err := kc.Rules().Future("XBTUSDTM"); err != nil || data.Symbol != "XBTUSDTM" {
+		t.Fatalf("unexpected rules %+v %v", data, err)
+	}
+	if _, err = kc.Rules().Spot("XXX-USDT"); !errors.Is(err, kugo.ErrUnknownSymbol) {
+		t.Fatalf("want ErrUnknownSymbol, got %v", err)
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("the symbols are loaded %d times, want 1", n)
+	}
+}
@@ -52,6 +52,29 @@ func TestSpotSymbols(t *testing.T) {
 	t.Logf("result: %+v", symbols)
 }
 
+func TestSpotCurrencies(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SpotCurrencies()
+	t.Log(result, err)
+}
+
+func TestSpotCurrency(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SpotCurrency("USDT", "")
+	t.Log(result, err)
+}
+
+func TestSpotPrices(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SpotPrices("USD", "BTC", "ETH")
+	t.Log(result, err)
+}
+
+func TestSpotServiceStatus(t *testing.T) {
+	result, err := instance.SpotServiceStatus()
+	t.Log(result, err)
+}
+
 func TestAccounts(t *testing.T) {
 	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
 	accounts, err := instance.SpotAccount("BTC", "trade")
@@ -61,6 +84,18 @@ func TestAccounts(t *testing.T) {
 	t.Logf("result: %+v", accounts)
 }
 
+func TestSpotBaseFee(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SpotBaseFee()
+	t.Log(result, err)
+}
+
+func TestSpotTradeFees(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SpotTradeFees("BTC-USDT", "ETH-USDT")
+	t.Log(result, err)
+}
+
 func TestSpotOrder(t *testing.T) {
 	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
 	req := &kugo.SpotOrdersRequest{
@@ -139,6 +174,12 @@ func TestFutureAccount(t *testing.T) {
 	t.Logf("result: %+v", account)
 }
 
+func TestFutureTradeFee(t *testing.T) {
+	instance.Set(kugo.SetApiKey(futureAccessKey, futureSecretKey, futurePassphrase))
+	result, err := instance.FutureTradeFee("XBTUSDTM")
+	t.Log(result, err)
+}
+
 func TestFutureOrder(t *testing.T) {
 	instance.Set(kugo.SetApiKey(futureAccessKey, futureSecretKey, futurePassphrase))
 	req := &kugo.FutureOrderRequest{
@@ -196,8 +237,181 @@ func TestFuturePosition(t *testing.T) {
 	t.Log(result, err)
 }
 
+func TestFutureServiceStatus(t *testing.T) {
+	result, err := instance.FutureServiceStatus()
+	t.Log(result, err)
+}
+
 func TestFutureSymbols(t *testing.T) {
 	instance.Set(kugo.SetApiKey(futureAccessKey, futureSecretKey, futurePassphrase))
 	result, err := instance.FutureSymbols()
 	t.Log(result, err)
 }
+
+func TestSubUserList(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SubUserList(1, 10)
+	t.Log(result, err)
+}
+
+func TestSubAccountOne(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SubAccountOne("5caefba7d9575a0688f83c45")
+	t.Log(result, err)
+}
+
+func TestSubAccountList(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SubAccountList()
+	t.Log(result, err)
+}
+
+func TestSubAccountListV2(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.SubAccountListV2(1, 10)
+	t.Log(result, err)
+}
+
+func TestSubApiKey(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	created, err := instance.SubApiKeyCreate(&kugo.SubApiKeyCreateRequest{
+		SubName:    "strategy01",
+		Passphrase: "12345678",
+		Remark:     "kugo",
+		Permission: "General,Trade",
+	})
+	t.Log(created, err)
+	if err != nil {
+		return
+	}
+
+	list, err := instance.SubApiKeyList("strategy01", created.ApiKey)
+	t.Log(list, err)
+
+	updated, err := instance.SubApiKeyUpdate(&kugo.SubApiKeyUpdateRequest{
+		SubName:    "strategy01",
+		ApiKey:     created.ApiKey,
+		Passphrase: "12345678",
+		Permission: "General",
+	})
+	t.Log(updated, err)
+
+	deleted, err := instance.SubApiKeyDelete("strategy01", created.ApiKey, "12345678")
+	t.Log(deleted, err)
+}
+
+func TestSubTransfer(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	req := &kugo.SubTransferRequest{
+		ClientOid:      "123",
+		Currency:       "USDT",
+		Amount:         decimal.NewFromFloat(1),
+		Direction:      "OUT",
+		AccountType:    "TRADE",
+		SubAccountType: "TRADE",
+		SubUserId:      "5caefba7d9575a0688f83c45",
+	}
+	result, err := instance.SubTransfer(req)
+	t.Log(result, err)
+}
+
+func TestDepositAddress(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	created, err := instance.DepositAddressCreate(&kugo.DepositAddressRequest{Currency: "USDT", Chain: "TRC20"})
+	t.Log(created, err)
+
+	one, err := instance.DepositAddressOne("USDT", "TRC20")
+	t.Log(one, err)
+
+	list, err := instance.DepositAddressList("USDT")
+	t.Log(list, err)
+
+	listV3, err := instance.DepositAddressListV3("USDT", "trx")
+	t.Log(listV3, err)
+}
+
+func TestDepositList(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	req := &kugo.DepositListRequest{
+		Currency: "USDT",
+		Status:   "SUCCESS",
+	}
+	result, err := instance.DepositList(req, 1, 10)
+	t.Log(result, err)
+}
+
+func TestWithdrawalQuotas(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	result, err := instance.WithdrawalQuotas("USDT", "TRC20")
+	t.Log(result, err)
+}
+
+func TestWithdrawalDisabled(t *testing.T) {
+	i, err := kugo.NewKucoin(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = i.Withdraw(&kugo.WithdrawRequest{Currency: "USDT", Address: "address", Amount: decimal.NewFromFloat(1)}); err != kugo.ErrWithdrawalDisabled {
+		t.Fatalf("Withdraw: want ErrWithdrawalDisabled, got %v", err)
+	}
+	if err = i.CancelWithdrawal("5bffb63303aa675e8bbe18f9"); err != kugo.ErrWithdrawalDisabled {
+		t.Fatalf("CancelWithdrawal: want ErrWithdrawalDisabled, got %v", err)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase), kugo.SetWithdrawal(true))
+	defer instance.Set(kugo.SetWithdrawal(false))
+	req := &kugo.WithdrawRequest{
+		Currency: "USDT",
+		Address:  "TXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+		Amount:   decimal.NewFromFloat(10),
+		Chain:    "TRC20",
+	}
+	result, err := instance.Withdraw(req)
+	t.Log(result, err)
+	if err != nil {
+		return
+	}
+	err = instance.CancelWithdrawal(result.WithdrawalId)
+	t.Log(err)
+}
+
+func TestWithdrawalList(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	req := &kugo.WithdrawalListRequest{
+		Currency: "USDT",
+	}
+	result, err := instance.WithdrawalList(req, 1, 10)
+	t.Log(result, err)
+}
+
+func TestSpotBullet(t *testing.T) {
+	instance.Set(kugo.SetApiKey(accessKey, secretKey, passphrase))
+	public, err := instance.SpotBulletPublic()
+	t.Log(public, err)
+
+	private, err := instance.SpotBulletPrivate()
+	t.Log(private, err)
+}
+
+func TestFutureBullet(t *testing.T) {
+	instance.Set(kugo.SetApiKey(futureAccessKey, futureSecretKey, futurePassphrase))
+	public, err := instance.FutureBulletPublic()
+	t.Log(public, err)
+
+	private, err := instance.FutureBulletPrivate()
+	t.Log(private, err)
+}
+
+func TestSpotKlines(t *testing.T) {
+	now := time.Now().Unix()
+	result, err := instance.SpotKlines("BTC-USDT", "1min", now-3600, now)
+	t.Log(len(result), err)
+}
+
+func TestFutureKlines(t *testing.T) {
+	now := time.Now().UnixMilli()
+	result, err := instance.FutureKlines("XBTUSDTM", 1, now-3600*1000, now)
+	t.Log(len(result), err)
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestSpotOrderBuilder(t *testing.T) {
+	price, size := decimal.NewFromInt(20000), decimal.RequireFromString("0.5")
+
+	req, err := kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).PostOnly().GTT(60).STP(kugo.STPCancelOldest).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Side != kugo.SideBuy || req.Type != kugo.OrderTypeLimit || req.TimeInForce != kugo.TimeInForceGTT ||
+		req.CancelAfter != 60 || !req.PostOnly || req.Stp != kugo.STPCancelOldest || !req.Size.Equal(size) {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	margin, err := kugo.NewSpotMarketFunds("BTC-USDT", kugo.SideSell, decimal.NewFromInt(100)).BuildMargin(kugo.MarginModeIsolated, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if margin.Funds != "100" || !margin.Size.IsZero() || margin.MarginModel != kugo.MarginModeIsolated || !margin.AutoBorrow {
+		t.Fatalf("unexpected margin request %+v", margin)
+	}
+
+	req, err = kugo.NewSpotLimit("BTC-USDT", kugo.SideSell, price, size).Iceberg(decimal.RequireFromString("0.1")).Build()
+	if err != nil || !req.Iceberg || req.VisibleSize != "0.1" {
+		t.Fatalf("unexpected request %+v %v", req, err)
+	}
+
+	invalid := []*kugo.SpotOrderBuilder{
+		kugo.NewSpotLimit("BTC-USDT", "long", price, size),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, decimal.Zero, size),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).Funds(decimal.NewFromInt(10)),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).Hidden().Iceberg(decimal.RequireFromString("0.1")),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).Iceberg(size),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).PostOnly().IOC(),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).FOK().PostOnly(),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).GTT(0),
+		kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, price, size).STP("XX"),
+		kugo.NewSpotMarket("BTC-USDT", kugo.SideBuy, size).Funds(decimal.NewFromInt(10)),
+		kugo.NewSpotMarket("BTC-USDT", kugo.SideBuy, decimal.Zero),
+		kugo.NewSpotMarket("BTC-USDT", kugo.SideBuy, size).PostOnly(),
+		kugo.NewSpotMarket("BTC-USDT", kugo.SideBuy, size).IOC(),
+	}
+	for i, b := range invalid {
+		if _, err := b.Build(); !errors.Is(err, kugo.ErrInvalidOrder) {
+			t.Errorf("case %d: want ErrInvalidOrder, got %v", i, err)
+		}
+	}
+	if _, err = kugo.NewSpotMarket("BTC-USDT", kugo.SideBuy, size).BuildMargin("portfolio", false); !errors.Is(err, kugo.ErrInvalidOrder) {
+		t.Errorf("want ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestFutureOrderBuilderFlags(t *testing.T) {
+	price := decimal.NewFromInt(20000)
+
+	req, err := kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).PostOnly().Iceberg(2).STP(kugo.STPCancelOldest).
+		Stop(kugo.StopDown, decimal.NewFromInt(19000), kugo.StopPriceTypeMark).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Size != 10 || req.VisibleSize != "2" || req.Stop != kugo.StopDown || req.StopPriceType != kugo.StopPriceTypeMark ||
+		req.Stp != kugo.STPCancelOldest {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	invalid := []*kugo.FutureOrderBuilder{
+		kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).PostOnly().IOC(),
+		kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).Hidden().Iceberg(2),
+		kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).Iceberg(10),
+		kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).Stop("sideways", price, kugo.StopPriceTypeTrade),
+		kugo.NewFutureLimit("XBTUSDTM", kugo.SideSell, price, 10).BaseQty(decimal.NewFromInt(1)),
+		kugo.NewFutureMarket("XBTUSDTM", kugo.SideSell, 10).PostOnly(),
+		kugo.NewFutureMarket("XBTUSDTM", kugo.SideSell, 10).STP(kugo.STPDecrease),
+		kugo.NewFutureMarket("XBTUSDTM", kugo.SideSell, 10).STP("XX"),
+	}
+	for i, b := range invalid {
+		if _, err := b.Build(); !errors.Is(err, kugo.ErrInvalidOrder) {
+			t.Errorf("case %d: want ErrInvalidOrder, got %v", i, err)
+		}
+	}
+}
+
+func TestOrderBuilderBody(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var body map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = nil
+		json.Unmarshal(b, &body)
+		w.Write([]byte(`{"code":"200000","data":{"orderId":"1"}}`))
+	}
+	s.mux.HandleFunc(kugo.UriSpotOrders, handler)
+	s.mux.HandleFunc(kugo.UriSpotMarginOrder, handler)
+	kc := s.kucoin(t)
+
+	// Zero decimals are not sent, so a market order by funds has no price and size
+	req, err := kugo.NewSpotMarketFunds("BTC-USDT", kugo.SideBuy, decimal.NewFromInt(10)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kc.SpotOrder(req); err != nil {
+		t.Fatal(err)
+	}
+	_, price := body["price"]
+	_, size := body["size"]
+	if price || size || body["funds"] != "10" || body["type"] != "market" {
+		t.Fatalf("unexpected body %v", body)
+	}
+
+	margin, err := kugo.NewSpotMarketFunds("BTC-USDT", kugo.SideBuy, decimal.NewFromInt(10)).BuildMargin(kugo.MarginModeCross, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kc.SpotMarginOrder(margin); err != nil {
+		t.Fatal(err)
+	}
+	_, price = body["price"]
+	_, size = body["size"]
+	if price || size || body["funds"] != "10" {
+		t.Fatalf("unexpected margin body %v", body)
+	}
+
+	future, err := kugo.NewFutureMarket("XBTUSDTM", kugo.SideBuy, 10).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kc.FutureOrder(future); err != nil {
+		t.Fatal(err)
+	}
+	_, price = body["price"]
+	_, stopPrice := body["stopPrice"]
+	if price || stopPrice || body["size"] != float64(10) {
+		t.Fatalf("unexpected future body %v", body)
+	}
+
+	// Non-zero decimals are sent as strings
+	req, err = kugo.NewSpotLimit("BTC-USDT", kugo.SideBuy, decimal.NewFromInt(20000), decimal.RequireFromString("0.5")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kc.SpotOrder(req); err != nil {
+		t.Fatal(err)
+	}
+	if body["price"] != "20000" || body["size"] != "0.5" {
+		t.Fatalf("unexpected limit body %v", body)
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestLevel3OrderBook(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	s.mux.HandleFunc(kugo.UriSpotOrderBookLevel3, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"sequence":100,"time":1663747970273,` +
+			`"bids":[["b2","100","2",1663747970000000002],["b1","100","1",1663747970000000001],["b3","99","3",1663747970000000003]],` +
+			`"asks":[["a1","101","1",1663747970000000004]]}}`))
+	})
+
+	kc := s.kucoin(t)
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	book := kugo.NewLevel3OrderBook(kc, ws, "BTC-USDT")
+	var mu sync.Mutex
+	var trades []*kugo.Level3TradeEvent
+	var queues []*kugo.Level3QueueEvent
+	book.OnTrade(func(e *kugo.Level3TradeEvent) {
+		mu.Lock()
+		trades = append(trades, e)
+		mu.Unlock()
+	})
+	book.OnQueue(func(e *kugo.Level3QueueEvent) {
+		mu.Lock()
+		queues = append(queues, e)
+		mu.Unlock()
+	})
+	book.Track("b2")
+	if err := book.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer book.Close()
+
+	// Orders at the same price are queued by time
+	position, err := book.QueuePosition("b2")
+	if err != nil || position.Position != 1 || !position.Ahead.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("unexpected queue position %+v %v", position, err)
+	}
+	if bid, _ := book.BestBid(); !bid.Size.Equal(decimal.NewFromInt(3)) {
+		t.Fatalf("unexpected best bid %+v", bid)
+	}
+
+	topic := "/spotMarket/level3:BTC-USDT"
+	s.push(topic, kugo.SubjectLevel3Received, `{"symbol":"BTC-USDT","sequence":"101","orderId":"s1","clientOid":"c1","ts":"1663747970000000005"}`)
+	s.push(topic, kugo.SubjectLevel3Match, `{"symbol":"BTC-USDT","sequence":"102","side":"sell","price":"100","size":"1","remainSize":"0","takerOrderId":"s1","makerOrderId":"b1","tradeId":"t1","ts":"1663747970000000006"}`)
+	s.push(topic, kugo.SubjectLevel3Done, `{"symbol":"BTC-USDT","sequence":"103","orderId":"b1","reason":"filled","ts":"1663747970000000007"}`)
+	s.push(topic, kugo.SubjectLevel3Open, `{"symbol":"BTC-USDT","sequence":"104","side":"sell","price":"100.5","size":"4","orderId":"a2","orderTime":"1663747970000000008","ts":"1663747970000000008"}`)
+	s.push(topic, kugo.SubjectLevel3Update, `{"symbol":"BTC-USDT","sequence":"105","orderId":"b2","size":"0.5","ts":"1663747970000000009"}`)
+	s.push(topic, kugo.SubjectLevel3Done, `{"symbol":"BTC-USDT","sequence":"106","orderId":"b2","reason":"canceled","ts":"1663747970000000010"}`)
+	waitUntil(t, "sequence 106", func() bool { return book.Sequence() == 106 })
+
+	bids, asks, err := book.Depth(0)
+	if err != nil || len(bids) != 1 || !bids[0].Price.Equal(decimal.NewFromInt(99)) || len(asks) != 2 || !asks[0].Size.Equal(decimal.NewFromInt(4)) {
+		t.Fatalf("unexpected depth %+v %+v %v", bids, asks, err)
+	}
+	if _, err = book.Order("b2"); err != kugo.ErrOrderNotInBook {
+		t.Fatalf("want ErrOrderNotInBook, got %v", err)
+	}
+	if orders, _ := book.Orders(kugo.SideSell, decimal.RequireFromString("100.5")); len(orders) != 1 || orders[0].OrderId != "a2" {
+		t.Fatalf("unexpected orders %+v", orders)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(trades) != 1 || trades[0].TradeId != "t1" || trades[0].MakerOrderId != "b1" {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	// The initial position, moving to the front, the size update and leaving the book
+	if len(queues) != 4 || queues[1].Position != 0 || !queues[2].Size.Equal(decimal.RequireFromString("0.5")) || !queues[3].Done {
+		for _, q := range queues {
+			t.Logf("%+v", q)
+		}
+		t.Fatalf("unexpected queue events")
+	}
+}
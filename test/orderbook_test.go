@@ -0,0 +1,117 @@
+package test
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSpotLocalOrderBook(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var snapshots int32
+	s.mux.HandleFunc(kugo.UriSpotOrderBook, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&snapshots, 1) == 1 {
+			w.Write([]byte(`{"code":"200000","data":{"sequence":"100","time":1663747970273,"bids":[["100","1"],["99","2"]],"asks":[["101","1"],["102","3"]]}}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200000","data":{"sequence":"200","time":1663747970273,"bids":[["98","1"]],"asks":[["103","1"]]}}`))
+	})
+
+	kc := s.kucoin(t)
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	book := kugo.NewSpotOrderBook(kc, ws, "BTC-USDT")
+	if err := book.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer book.Close()
+
+	if bid, err := book.BestBid(); err != nil || bid.Price.String() != "100" {
+		t.Fatalf("unexpected best bid %+v %v", bid, err)
+	}
+
+	// Stale deltas are ignored, changes are applied by sequence
+	s.push("/market/level2:BTC-USDT", "trade.l2update", `{"changes":{"asks":[],"bids":[["100","5","100"]]},"sequenceEnd":100,"sequenceStart":100,"symbol":"BTC-USDT","time":1663747970273}`)
+	s.push("/market/level2:BTC-USDT", "trade.l2update", `{"changes":{"asks":[["101","5","102"]],"bids":[["100","0","101"]]},"sequenceEnd":102,"sequenceStart":101,"symbol":"BTC-USDT","time":1663747970273}`)
+	waitUntil(t, "sequence 102", func() bool { return book.Sequence() == 102 })
+
+	bid, _ := book.BestBid()
+	ask, _ := book.BestAsk()
+	if !bid.Price.Equal(decimal.NewFromInt(99)) || !ask.Size.Equal(decimal.NewFromInt(5)) {
+		t.Fatalf("unexpected best bid %+v and best ask %+v", bid, ask)
+	}
+	bids, asks, _ := book.Depth(1)
+	if len(bids) != 1 || len(asks) != 1 {
+		t.Fatalf("unexpected depth %+v %+v", bids, asks)
+	}
+	vwap, err := book.VWAP(kugo.SideBuy, decimal.NewFromInt(6))
+	if err != nil || !vwap.Equal(decimal.NewFromInt(607).Div(decimal.NewFromInt(6))) {
+		t.Fatalf("unexpected vwap %s %v", vwap, err)
+	}
+	if _, err = book.VWAP(kugo.SideSell, decimal.NewFromInt(10)); err != kugo.ErrOrderBookDepth {
+		t.Fatalf("want ErrOrderBookDepth, got %v", err)
+	}
+	if _, err = book.VWAP("Sell", decimal.NewFromInt(1)); err == nil {
+		t.Fatal("want an error of an unknown side")
+	}
+
+	// A gap in the sequence synchronizes the book again
+	s.push("/market/level2:BTC-USDT", "trade.l2update", `{"changes":{"asks":[],"bids":[["97","1","110"]]},"sequenceEnd":110,"sequenceStart":110,"symbol":"BTC-USDT","time":1663747970273}`)
+	waitUntil(t, "resynchronization", func() bool { return book.Synced() && book.Sequence() == 200 })
+	if bid, _ = book.BestBid(); !bid.Price.Equal(decimal.NewFromInt(98)) {
+		t.Fatalf("unexpected best bid after resynchronization %+v", bid)
+	}
+}
+
+func TestFutureLocalOrderBook(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	s.mux.HandleFunc(kugo.UriFutureOrderBook, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"symbol":"XBTUSDTM","sequence":50,"asks":[[5000.0,1000],[6000.0,1983]],"bids":[[3200.0,800],[3100.0,100]],"ts":1604643655040584408}}`))
+	})
+
+	kc := s.kucoin(t)
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	book := kugo.NewFutureOrderBook(kc, ws, "XBTUSDTM")
+	if err := book.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer book.Close()
+
+	s.push("/contractMarket/level2:XBTUSDTM", "level2", `{"sequence":51,"change":"4000.0,sell,10","timestamp":1551770400000}`)
+	s.push("/contractMarket/level2:XBTUSDTM", "level2", `{"sequence":52,"change":"3200.0,buy,0","timestamp":1551770400000}`)
+	waitUntil(t, "sequence 52", func() bool { return book.Sequence() == 52 })
+
+	bid, _ := book.BestBid()
+	ask, _ := book.BestAsk()
+	if !bid.Price.Equal(decimal.NewFromInt(3100)) || !ask.Price.Equal(decimal.NewFromInt(4000)) || ask.Size.IntPart() != 10 {
+		t.Fatalf("unexpected best bid %+v and best ask %+v", bid, ask)
+	}
+	select {
+	case <-book.Changes():
+	default:
+		t.Fatal("no change notification")
+	}
+}
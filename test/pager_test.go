@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newPagerServer(pages *int32) *fakeServer {
+	s := newFakeServer()
+	s.mux.HandleFunc(kugo.UriSpotOrderFills, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(pages, 1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("currentPage"))
+		fmt.Fprintf(w, `{"code":"200000","data":{"currentPage":%d,"pageSize":2,"totalNum":5,"totalPage":3,"items":[`, page)
+		for i := 0; i < 2 && (page-1)*2+i < 5; i++ {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"tradeId":"%d"}`, (page-1)*2+i)
+		}
+		w.Write([]byte(`]}}`))
+	})
+	return s
+}
+
+func TestPager(t *testing.T) {
+	var pages int32
+	s := newPagerServer(&pages)
+	defer s.Close()
+	kc := s.kucoin(t)
+
+	pager := kc.SpotOrderFillsAll(context.Background(), &kugo.SpotOrderFillsRequest{Symbol: "BTC-USDT"})
+	var ids []string
+	for pager.Next() {
+		ids = append(ids, pager.Item().TradeId)
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[0 1 2 3 4]" || atomic.LoadInt32(&pages) != 3 || pager.TotalNum() != 5 {
+		t.Fatalf("unexpected items %v of %d pages", ids, pages)
+	}
+
+	// The next page is not fetched after the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	pager = kc.SpotOrderFillsAll(ctx, &kugo.SpotOrderFillsRequest{})
+	for i := 0; i < 2; i++ {
+		if !pager.Next() {
+			t.Fatal(pager.Err())
+		}
+	}
+	cancel()
+	if pager.Next() || pager.Err() != context.Canceled {
+		t.Fatalf("want context.Canceled, got %v", pager.Err())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var pages int32
+	s := newPagerServer(&pages)
+	defer s.Close()
+	kc := s.kucoin(t)
+	if err := kc.Set(kugo.SetRateLimit(10, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	pager := kc.SpotOrderFillsAll(context.Background(), &kugo.SpotOrderFillsRequest{})
+	for pager.Next() {
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// The first request is allowed by the burst, the others wait 100ms each
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Fatalf("3 requests took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	pager = kc.SpotOrderFillsAll(ctx, &kugo.SpotOrderFillsRequest{})
+	if pager.Next() || pager.Err() != context.DeadlineExceeded {
+		t.Fatalf("want context.DeadlineExceeded, got %v", pager.Err())
+	}
+}
+
+func TestRateLimitByEndpoint(t *testing.T) {
+	var pages int32
+	s := newPagerServer(&pages)
+	defer s.Close()
+	// The same server as another endpoint
+	kc, err := kugo.NewKucoin(
+		kugo.SetSpotEndpoint(s.URL),
+		kugo.SetFutureEndpoint(strings.Replace(s.URL, "127.0.0.1", "localhost", 1)),
+		kugo.SetSpotRateLimit(1, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The spot requests do not use up the future limit
+	start := time.Now()
+	if _, err = kc.SpotOrderFills(&kugo.SpotOrderFillsRequest{}, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err = kc.FutureOrderFills(&kugo.FutureOrderFillsRequest{}, 1, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("4 requests took %v", elapsed)
+	}
+	if _, err = kc.SpotOrderFills(&kugo.SpotOrderFillsRequest{}, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("the second spot request is not limited, took %v", elapsed)
+	}
+}
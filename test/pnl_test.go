@@ -0,0 +1,144 @@
+package test
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"testing"
+)
+
+func spotFill(id, side, price, size, fee string) *kugo.SpotOrderFillsItem {
+	return &kugo.SpotOrderFillsItem{Symbol: "BTC-USDT", TradeId: id, Side: side, FeeCurrency: "USDT",
+		Price: decimal.RequireFromString(price), Size: decimal.RequireFromString(size), Fee: decimal.RequireFromString(fee)}
+}
+
+func TestPnLTrackerCostMethods(t *testing.T) {
+	// Buy 1 at 100 and 1 at 200, sell 1 at 300
+	fills := []*kugo.SpotOrderFillsItem{
+		spotFill("1", "buy", "100", "1", "0.1"),
+		spotFill("2", "buy", "200", "1", "0.2"),
+		spotFill("3", "sell", "300", "1", "0.3"),
+	}
+	cases := []struct {
+		method     string
+		realized   string
+		avgCost    string
+		unrealized string // At 250
+	}{
+		{kugo.CostFIFO, "200", "200", "50"},
+		{kugo.CostLIFO, "100", "100", "150"},
+		{kugo.CostAverage, "150", "150", "100"},
+	}
+	for _, c := range cases {
+		tracker, err := kugo.NewPnLTracker(kugo.SetPnLCostMethod(c.method))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range fills {
+			if err = tracker.AddSpotFill(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tracker.SetPrice("BTC-USDT", decimal.NewFromInt(250))
+		p, err := tracker.Position("BTC-USDT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Realized.String() != c.realized || p.AvgCost.String() != c.avgCost || p.Unrealized.String() != c.unrealized ||
+			p.Qty.String() != "1" || p.Currency != "USDT" || p.Fees["USDT"].String() != "0.6" {
+			t.Errorf("%s: unexpected position %+v", c.method, p)
+		}
+	}
+}
+
+func TestPnLTrackerStream(t *testing.T) {
+	tracker, err := kugo.NewPnLTracker(kugo.SetPnLReportCurrency("EUR", func(from, to string) (decimal.Decimal, error) {
+		if from == "USDT" && to == "EUR" {
+			return decimal.RequireFromString("0.9"), nil
+		}
+		return decimal.Zero, errors.New("no rate")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A streamed fill has no fee, which is counted when the fill is loaded from REST
+	tracker.AddSpotFill(spotFill("1", "buy", "100", "2", "0.2"))
+	tracker.OnSpotOrder(&kugo.SpotOrderEvent{Symbol: "BTC-USDT", Type: "match", TradeId: "2", Side: "sell",
+		MatchPrice: decimal.NewFromInt(110), MatchSize: decimal.NewFromInt(1)})
+	tracker.AddSpotFill(spotFill("2", "sell", "110", "1", "0.11"))
+	// Selling more than the inventory opens a short position
+	tracker.AddSpotFill(spotFill("3", "sell", "120", "2", "0"))
+
+	p, err := tracker.Position("BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Realized.String() != "30" || p.Qty.String() != "-1" || p.AvgCost.String() != "120" ||
+		p.Fees["USDT"].String() != "0.31" || p.RealizedReport.String() != "27" || p.FeesReport.String() != "0.279" {
+		t.Fatalf("unexpected position %+v", p)
+	}
+}
+
+func TestPnLTrackerFutures(t *testing.T) {
+	contracts := map[string]*kugo.FutureSymbolData{
+		"XBTUSDTM": {Symbol: "XBTUSDTM", Multiplier: decimal.RequireFromString("0.001"), SettleCurrency: "USDT"},
+		"XBTUSDM":  {Symbol: "XBTUSDM", Multiplier: decimal.NewFromInt(-1), IsInverse: true, SettleCurrency: "XBT"},
+	}
+	tracker, err := kugo.NewPnLTracker(kugo.SetPnLContracts(func(symbol string) (*kugo.FutureSymbolData, error) {
+		if c, ok := contracts[symbol]; ok {
+			return c, nil
+		}
+		return nil, kugo.ErrUnknownSymbol
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Short 1000 linear contracts (1 XBT) at 20000 and buy back at 19000
+	tracker.AddFutureFill(&kugo.FutureOrderFillsItem{Symbol: "XBTUSDTM", TradeId: "1", Side: "sell", Price: decimal.NewFromInt(20000), Size: 1000})
+	tracker.AddFutureFill(&kugo.FutureOrderFillsItem{Symbol: "XBTUSDTM", TradeId: "2", Side: "buy", Price: decimal.NewFromInt(19000), Size: 1000})
+	// Long 20000 inverse contracts (20000 USD) at 20000 and sell at 25000
+	tracker.AddFutureFill(&kugo.FutureOrderFillsItem{Symbol: "XBTUSDM", TradeId: "3", Side: "buy", Price: decimal.NewFromInt(20000), Size: 20000})
+	tracker.AddFutureFill(&kugo.FutureOrderFillsItem{Symbol: "XBTUSDM", TradeId: "4", Side: "sell", Price: decimal.NewFromInt(25000), Size: 20000})
+
+	positions, err := tracker.Positions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 || positions[0].Symbol != "XBTUSDM" || positions[0].Realized.String() != "0.2" || positions[0].Currency != "XBT" ||
+		positions[1].Realized.String() != "1000" || !positions[1].Qty.IsZero() {
+		t.Fatalf("unexpected positions %+v %+v", positions[0], positions[1])
+	}
+	if err = tracker.AddFutureFill(&kugo.FutureOrderFillsItem{Symbol: "ETHUSDTM", Side: "buy"}); !errors.Is(err, kugo.ErrUnknownSymbol) {
+		t.Fatalf("want ErrUnknownSymbol, got %v", err)
+	}
+}
+
+func TestPnLTrackerTradeIds(t *testing.T) {
+	tracker, err := kugo.NewPnLTracker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Trade ids are unique in a symbol only
+	btc, eth := spotFill("1", "buy", "100", "1", "0"), spotFill("1", "buy", "10", "2", "0")
+	eth.Symbol = "ETH-USDT"
+	tracker.AddSpotFill(btc)
+	tracker.AddSpotFill(eth)
+	if p, err := tracker.Position("ETH-USDT"); err != nil || p.Qty.String() != "2" {
+		t.Fatalf("unexpected position %+v %v", p, err)
+	}
+
+	// The ids within the window are kept when older ones are pruned
+	at := int64(1672531200000)
+	tracker.OnSpotOrder(&kugo.SpotOrderEvent{Symbol: "BTC-USDT", Type: "match", TradeId: "2", Side: "buy",
+		MatchPrice: decimal.NewFromInt(100), MatchSize: decimal.NewFromInt(1), Ts: at * 1000000})
+	later := spotFill("3", "buy", "100", "1", "0")
+	later.CreatedAt = at + 2*3600*1000
+	tracker.AddSpotFill(later)
+	rest := spotFill("2", "buy", "100", "1", "0.1")
+	rest.CreatedAt = at
+	tracker.AddSpotFill(rest)
+	if p, err := tracker.Position("BTC-USDT"); err != nil || p.Qty.String() != "3" || p.Fees["USDT"].String() != "0.1" {
+		t.Fatalf("unexpected position %+v %v", p, err)
+	}
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"testing"
+)
+
+func newPortfolioServer() *fakeServer {
+	s := newFakeServer()
+	s.mux.HandleFunc(kugo.UriSpotAccount, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[` +
+			`{"currency":"USDT","type":"main","balance":"100","available":"100","holds":"0"},` +
+			`{"currency":"BTC","type":"trade","balance":"0.5","available":"0.4","holds":"0.1"},` +
+			`{"currency":"KCS","type":"trade","balance":"10","available":"10","holds":"0"},` +
+			`{"currency":"ABC","type":"trade","balance":"7","available":"7","holds":"0"},` +
+			`{"currency":"ETH","type":"trade","balance":"0","available":"0","holds":"0"},` +
+			`{"currency":"USDT","type":"margin","balance":"300","available":"300","holds":"0"}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriSpotMarginAccount, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"debtRatio":"0.25","accounts":[` +
+			`{"currency":"USDT","totalBalance":"300","availableBalance":"300","holdBalance":"0","liability":"0"},` +
+			`{"currency":"ETH","totalBalance":"0","availableBalance":"0","holdBalance":"0","liability":"0.05"}]}}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureSymbols, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"XBTUSDTM","settleCurrency":"USDT"},` +
+			`{"symbol":"ETHUSDTM","settleCurrency":"USDT"},{"symbol":"XBTUSDM","settleCurrency":"XBT"},{"symbol":"XBTUSDCM","settleCurrency":"USDC"}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriSpotIsolatedAccount, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"assets":[{"symbol":"ETH-USDT","status":"DEBT","debtRatio":"0.1",` +
+			`"baseAsset":{"currency":"ETH","totalBalance":"1","holdBalance":"0","availableBalance":"1","liability":"0.1","interest":"0.001"},` +
+			`"quoteAsset":{"currency":"USDT","totalBalance":"100","holdBalance":"0","availableBalance":"100","liability":"0","interest":"0"}}]}}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureAccount, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("currency") {
+		case "USDT":
+			w.Write([]byte(`{"code":"200000","data":{"accountEquity":"210","unrealisedPNL":"10","marginBalance":"200",` +
+				`"positionMargin":"50","orderMargin":"20","frozenFunds":"0","availableBalance":"140","currency":"USDT"}}`))
+		case "XBT":
+			w.Write([]byte(`{"code":"200000","data":{"accountEquity":"0.1","unrealisedPNL":"-0.01","marginBalance":"0.11",` +
+				`"positionMargin":"0.05","orderMargin":"0","frozenFunds":"0","availableBalance":"0.05","currency":"XBT"}}`))
+		default:
+			w.Write([]byte(`{"code":"400100","msg":"unknown currency"}`))
+		}
+	})
+	s.mux.HandleFunc(kugo.UriSpotAllTickers, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"time":1672531200000,"ticker":[` +
+			`{"symbol":"BTC-USDT","buy":"19999","sell":"20001","last":"20000","changeRate":null},` +
+			`{"symbol":"ETH-USDT","last":"1500"},{"symbol":"KCS-BTC","last":"0.0003"}]}}`))
+	})
+	return s
+}
+
+func TestPortfolio(t *testing.T) {
+	s := newPortfolioServer()
+	defer s.Close()
+
+	snapshot, err := s.kucoin(t).Portfolio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// USDT 100 main + 300 margin + 100 isolated + 210 futures, BTC 0.5 trade + 0.1 futures at 20000,
+	// KCS 10 at 0.0003 BTC, ETH -0.05 margin + 0.899 isolated at 1500, ABC unpriced
+	if snapshot.Time != 1672531200000 || snapshot.Currency != "USDT" || snapshot.Equity.String() != "14043.5" ||
+		snapshot.Debt.String() != "226.5" || snapshot.UnrealisedPNL.String() != "-190" || snapshot.DebtRatio.String() != "0.25" {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	if len(snapshot.Balances) != 10 || len(snapshot.Unpriced) != 1 || snapshot.Unpriced[0] != "ABC" {
+		t.Fatalf("unexpected balances %+v, unpriced %v", snapshot.Balances, snapshot.Unpriced)
+	}
+	// The futures account of USDC fails to load and is left out
+	if len(snapshot.Errors) != 1 || snapshot.Errors[0].Account != kugo.PortfolioFutures || snapshot.Errors[0].Currency != "USDC" {
+		t.Fatalf("unexpected errors %+v", snapshot.Errors)
+	}
+	for _, b := range snapshot.Balances {
+		if b.Account == kugo.PortfolioFutures && b.Currency == "USDT" &&
+			(b.Balance.String() != "210" || b.Hold.String() != "70" || b.UnrealisedPNL.String() != "10") {
+			t.Fatalf("unexpected futures balance %+v", b)
+		}
+	}
+	// The XBT futures account is summed with the BTC spot account
+	if b, ok := snapshot.Balance("BTC"); !ok || b.Balance.String() != "0.6" || b.Price.String() != "20000" || b.Value.String() != "12000" {
+		t.Fatalf("unexpected BTC balance %+v", b)
+	}
+	if _, ok := snapshot.Balance("XBT"); ok {
+		t.Fatal("unexpected XBT balance")
+	}
+	if b, ok := snapshot.Balance("ETH"); !ok || b.Debt.String() != "0.151" || b.Value.String() != "1273.5" {
+		t.Fatalf("unexpected ETH balance %+v", b)
+	}
+
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &kugo.PortfolioSnapshot{}
+	if err = json.Unmarshal(b, decoded); err != nil || !decoded.Equity.Equal(snapshot.Equity) || len(decoded.Currencies) != 5 {
+		t.Fatalf("unexpected json %s, %v", b, err)
+	}
+
+	// Only the futures accounts of the settle currencies
+	if snapshot, err = s.kucoin(t).Portfolio("USDT"); err != nil || snapshot.Equity.String() != "12043.5" || len(snapshot.Errors) != 0 {
+		t.Fatalf("unexpected snapshot %+v, %v", snapshot, err)
+	}
+}
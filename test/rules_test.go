@@ -0,0 +1,138 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func newRulesServer() *fakeServer {
+	s := newFakeServer()
+	s.mux.HandleFunc(kugo.UriSpotSymbols, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"BTC-USDT","baseCurrency":"BTC","quoteCurrency":"USDT",` +
+			`"baseMinSize":"0.00001","quoteMinSize":"0.1","baseMaxSize":"10000","quoteMaxSize":"99999999",` +
+			`"baseIncrement":"0.00000001","quoteIncrement":"0.000001","priceIncrement":"0.1","priceLimitRate":"0.1",` +
+			`"minFunds":"0.1","enableTrading":true},` +
+			`{"symbol":"OLD-USDT","enableTrading":false}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriSpotTicker, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":{"sequence":"1","price":"20000","size":"0.1","bestBid":"19999.9","bestAsk":"20000","time":1663747970273}}`))
+	})
+	s.mux.HandleFunc(kugo.UriFutureSymbols, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"XBTUSDTM","lotSize":1,"tickSize":1,"maxOrderQty":1000000,"maxPrice":1000000,"multiplier":0.001,"status":"Open"}]}`))
+	})
+	return s
+}
+
+func TestSymbolRules(t *testing.T) {
+	s := newRulesServer()
+	defer s.Close()
+	rules := s.kucoin(t).Rules()
+
+	req := &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "sell", Type: "limit",
+		Price: decimal.RequireFromString("20000.03"), Size: decimal.RequireFromString("0.123456789")}
+	if err := rules.NormalizeSpotOrder(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Price.String() != "20000.1" || req.Size.String() != "0.12345678" {
+		t.Fatalf("unexpected normalized order %s %s", req.Price, req.Size)
+	}
+
+	cases := []struct {
+		req *kugo.SpotOrdersRequest
+		err error
+	}{
+		{&kugo.SpotOrdersRequest{Symbol: "XXX-USDT"}, kugo.ErrUnknownSymbol},
+		{&kugo.SpotOrdersRequest{Symbol: "OLD-USDT"}, kugo.ErrTradingDisabled},
+		{&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit", Price: decimal.NewFromInt(20000), Size: decimal.RequireFromString("0.000001")}, kugo.ErrOrderSizeTooSmall},
+		{&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit", Price: decimal.NewFromInt(1000), Size: decimal.RequireFromString("0.00005")}, kugo.ErrOrderFundsTooSmall},
+		{&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit", Price: decimal.NewFromInt(23000), Size: decimal.NewFromInt(1)}, kugo.ErrOrderPriceOutOfBand},
+		{&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "sell", Type: "limit", Price: decimal.NewFromInt(17000), Size: decimal.NewFromInt(1)}, kugo.ErrOrderPriceOutOfBand},
+		{&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "market", Funds: "0.01"}, kugo.ErrOrderSizeTooSmall},
+	}
+	for i, c := range cases {
+		if err := rules.NormalizeSpotOrder(c.req); !errors.Is(err, c.err) {
+			t.Errorf("case %d: want %v, got %v", i, c.err, err)
+		}
+	}
+
+	future := &kugo.FutureOrderRequest{Symbol: "XBTUSDTM", Side: "buy", Type: "limit", Price: decimal.RequireFromString("20000.7"), Size: 3}
+	if err := rules.NormalizeFutureOrder(future); err != nil || future.Price.String() != "20000" {
+		t.Fatalf("unexpected normalized future order %+v %v", future, err)
+	}
+}
+
+func TestOrderNormalization(t *testing.T) {
+	s := newRulesServer()
+	defer s.Close()
+	var sent kugo.SpotOrdersRequest
+	s.mux.HandleFunc(kugo.UriSpotOrders, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &sent)
+		w.Write([]byte(`{"code":"200000","data":{"orderId":"1"}}`))
+	})
+	kc := s.kucoin(t)
+	if err := kc.Set(kugo.SetOrderNormalization(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := kc.SpotOrder(&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit",
+		Price: decimal.RequireFromString("20000.09"), Size: decimal.RequireFromString("0.1")}); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Price.String() != "20000" {
+		t.Fatalf("the order is not normalized: %+v", sent)
+	}
+	if _, err := kc.SpotOrder(&kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit",
+		Price: decimal.NewFromInt(20000), Size: decimal.RequireFromString("0.000001")}); !errors.Is(err, kugo.ErrOrderSizeTooSmall) {
+		t.Fatalf("want ErrOrderSizeTooSmall, got %v", err)
+	}
+}
+
+func TestSymbolRulesCache(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	var symbols, tickers int32
+	s.mux.HandleFunc(kugo.UriSpotSymbols, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&symbols, 1)
+		w.Write([]byte(`{"code":"200000","data":[{"symbol":"BTC-USDT","baseMinSize":"0.00001","baseIncrement":"0.00000001",` +
+			`"priceIncrement":"0.1","priceLimitRate":"0.1","enableTrading":true}]}`))
+	})
+	s.mux.HandleFunc(kugo.UriSpotTicker, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tickers, 1)
+		w.Write([]byte(`{"code":"200000","data":{"sequence":"1","price":"20000","time":1663747970273}}`))
+	})
+	rules := s.kucoin(t).Rules()
+
+	// The symbols and the last price are loaded once
+	for i := 0; i < 3; i++ {
+		req := &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "buy", Type: "limit", Price: decimal.NewFromInt(20000), Size: decimal.NewFromInt(1)}
+		if err := rules.NormalizeSpotOrder(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Unknown symbols do not reload the symbols right after a load
+	for i := 0; i < 3; i++ {
+		if _, err := rules.Spot("XXX-USDT"); !errors.Is(err, kugo.ErrUnknownSymbol) {
+			t.Fatalf("want ErrUnknownSymbol, got %v", err)
+		}
+	}
+	if n, m := atomic.LoadInt32(&symbols), atomic.LoadInt32(&tickers); n != 1 || m != 1 {
+		t.Fatalf("%d symbols requests and %d ticker requests, want 1 and 1", n, m)
+	}
+
+	// A price set by the caller is used for the price limit
+	rules.SetLastPrice("BTC-USDT", decimal.NewFromInt(30000))
+	req := &kugo.SpotOrdersRequest{Symbol: "BTC-USDT", Side: "sell", Type: "limit", Price: decimal.NewFromInt(20000), Size: decimal.NewFromInt(1)}
+	if err := rules.NormalizeSpotOrder(req); !errors.Is(err, kugo.ErrOrderPriceOutOfBand) {
+		t.Fatalf("want ErrOrderPriceOutOfBand, got %v", err)
+	}
+	if n := atomic.LoadInt32(&tickers); n != 1 {
+		t.Fatalf("%d ticker requests, want 1", n)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServiceStatusWatcher(t *testing.T) {
+	var status atomic.Value
+	status.Store(kugo.ServiceStatusClose)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case kugo.UriSpotStatus:
+			w.Write([]byte(`{"code":"200000","data":{"status":"` + status.Load().(string) + `","msg":"upgrade match engine"}}`))
+		case kugo.UriSpotOrders:
+			w.Write([]byte(`{"code":"200000","data":{"orderId":"5bd6e9286d99522a52e458de"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	i, err := kugo.NewKucoin(
+		kugo.SetSpotEndpoint(server.URL),
+		kugo.SetFutureEndpoint(server.URL),
+		kugo.SetServiceStatusWatcher(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+
+	req := &kugo.SpotOrdersRequest{
+		ClientOid: "123",
+		Side:      "buy",
+		Symbol:    "BTC-USDT",
+		Type:      "limit",
+		Price:     decimal.NewFromFloat(10000),
+		Size:      decimal.NewFromFloat(0.00001),
+	}
+	waitFor := func(want error) {
+		deadline := time.Now().Add(time.Second)
+		for {
+			_, err := i.SpotOrder(req)
+			if err == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("SpotOrder: want %v, got %v", want, err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor(kugo.ErrServiceMaintenance)
+	status.Store(kugo.ServiceStatusCancelOnly)
+	waitFor(kugo.ErrServiceCancelOnly)
+	status.Store(kugo.ServiceStatusOpen)
+	waitFor(nil)
+}
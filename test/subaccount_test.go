@@ -0,0 +1,31 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/xiiiew/kugo"
+	"net/http"
+	"testing"
+)
+
+func TestSubApiKeyDeleteEscape(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	passphrase := "a&b+c#d=e f"
+	s.mux.HandleFunc(kugo.UriSubApiKey, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method != http.MethodDelete || q.Get("passphrase") != passphrase || q.Get("subName") != "sub" {
+			w.Write([]byte(`{"code":"400100","msg":"invalid query ` + r.URL.RawQuery + `"}`))
+			return
+		}
+		b, _ := json.Marshal(map[string]interface{}{"code": "200000", "data": map[string]string{"subName": "sub", "apiKey": q.Get("apiKey")}})
+		w.Write(b)
+	})
+
+	data, err := s.kucoin(t).SubApiKeyDelete("sub", "key", passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.ApiKey != "key" {
+		t.Fatalf("unexpected data %+v", data)
+	}
+}
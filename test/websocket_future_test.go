@@ -0,0 +1,105 @@
+package test
+
+import (
+	"github.com/xiiiew/kugo"
+	"testing"
+)
+
+func TestWsFutureMarket(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewFutureWsClient(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	level2 := make(chan *kugo.FutureLevel2Event, 10)
+	if err = ws.SubscribeFutureLevel2(func(e *kugo.FutureLevel2Event) { level2 <- e }, "XBTUSDTM"); err != nil {
+		t.Fatal(err)
+	}
+	executions := make(chan *kugo.FutureExecutionEvent, 10)
+	if err = ws.SubscribeFutureExecution(func(e *kugo.FutureExecutionEvent) { executions <- e }, "XBTUSDTM"); err != nil {
+		t.Fatal(err)
+	}
+	instruments := make(chan *kugo.FutureInstrumentEvent, 10)
+	if err = ws.SubscribeFutureInstrument(func(e *kugo.FutureInstrumentEvent) { instruments <- e }, "XBTUSDTM"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.push("/contractMarket/level2:XBTUSDTM", "level2", `{"sequence":18,"change":"5000.0,sell,83","timestamp":1551770400000}`)
+	l2 := <-level2
+	if l2.Symbol != "XBTUSDTM" || l2.Sequence != 18 || l2.Price.String() != "5000" || l2.Side != "sell" || l2.Size != 83 {
+		t.Fatalf("unexpected level2 %+v", l2)
+	}
+
+	s.push("/contractMarket/execution:XBTUSDTM", "match", `{"symbol":"XBTUSDTM","sequence":36,"side":"buy","size":1,"price":"3800","takerOrderId":"5c9dcf4170744d6f5a3d32fb","makerOrderId":"5c9dd00870744d71c43f5e25","tradeId":"5c9dd07170744d6f5a3d32fc","ts":1553846281766256031}`)
+	execution := <-executions
+	if execution.Size != 1 || execution.Price.String() != "3800" || execution.Ts != 1553846281766256031 {
+		t.Fatalf("unexpected execution %+v", execution)
+	}
+
+	s.push("/contract/instrument:XBTUSDTM", kugo.SubjectMarkIndexPrice, `{"granularity":1000,"indexPrice":4000.23,"markPrice":4010.52,"timestamp":1551770400000}`)
+	s.push("/contract/instrument:XBTUSDTM", kugo.SubjectFundingRate, `{"granularity":60000,"fundingRate":-0.002966,"timestamp":1551770400000}`)
+	mark := <-instruments
+	if mark.Subject != kugo.SubjectMarkIndexPrice || mark.Symbol != "XBTUSDTM" || mark.MarkPrice.String() != "4010.52" {
+		t.Fatalf("unexpected instrument %+v", mark)
+	}
+	funding := <-instruments
+	if funding.Subject != kugo.SubjectFundingRate || funding.FundingRate.String() != "-0.002966" {
+		t.Fatalf("unexpected instrument %+v", funding)
+	}
+}
+
+func TestWsFuturePrivate(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewFutureWsClient(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := make(chan *kugo.FutureOrderEvent, 10)
+	if err = ws.SubscribeFutureOrders(func(e *kugo.FutureOrderEvent) { orders <- e }); err != nil {
+		t.Fatal(err)
+	}
+	positions := make(chan *kugo.FuturePositionEvent, 10)
+	if err = ws.SubscribeFuturePosition(func(e *kugo.FuturePositionEvent) { positions <- e }, "XBTUSDTM"); err != nil {
+		t.Fatal(err)
+	}
+	wallets := make(chan *kugo.FutureWalletEvent, 10)
+	if err = ws.SubscribeFutureWallet(func(e *kugo.FutureWalletEvent) { wallets <- e }); err != nil {
+		t.Fatal(err)
+	}
+
+	s.push("/contractMarket/tradeOrders", "orderChange", `{"orderId":"5cdfc138b21023a909e5ad55","symbol":"XBTUSDTM","type":"match","status":"open","matchSize":"1","matchPrice":"3600","orderType":"limit","side":"buy","price":"3600","size":"20000","remainSize":"20001","filledSize":"20000","canceledSize":"0","tradeId":"5ce24c16b210233c36eexxxx","clientOid":"5ce24c16b210233c36ee321d","orderTime":1545914149935808589,"liquidity":"maker","ts":1545914149935808589}`)
+	order := <-orders
+	if order.Type != kugo.OrderEventMatch || order.MatchPrice.String() != "3600" || order.Size.IntPart() != 20000 {
+		t.Fatalf("unexpected order event %+v", order)
+	}
+
+	s.push("/contract/position:XBTUSDTM", kugo.SubjectPositionChange, `{"realisedGrossPnl":0E-8,"symbol":"XBTUSDTM","crossMode":false,"liquidationPrice":1000000.0,"posLoss":0E-8,"avgEntryPrice":7508.22,"unrealisedPnl":-0.00014735,"markPrice":7947.83,"posMargin":0.00266779,"autoDeposit":false,"riskLimit":100000,"unrealisedCost":0.00266375,"posComm":0.00000392,"posMaint":0.00001724,"posCost":0.00266375,"maintMarginReq":0.005,"bankruptPrice":1000000.0,"realisedCost":0.00000271,"markValue":0.00251640,"posInit":0.00266375,"realisedPnl":-0.00000253,"maintMargin":0.00252044,"realLeverage":1.06,"changeReason":"positionChange","currentCost":0.00266375,"openingTimestamp":1558433191000,"currentQty":-20,"delevPercentage":0.52,"currentComm":0.00000271,"realisedGrossCost":0E-8,"isOpen":true,"posCross":1.2E-7,"currentTimestamp":1558506060394,"unrealisedRoePcnt":-0.0553,"unrealisedPnlPcnt":-0.0553,"settleCurrency":"XBT"}`)
+	position := <-positions
+	if position.Subject != kugo.SubjectPositionChange || position.ChangeReason != "positionChange" || position.CurrentQty != -20 || position.MarkPrice.String() != "7947.83" {
+		t.Fatalf("unexpected position event %+v", position)
+	}
+
+	s.push("/contract/position:XBTUSDTM", kugo.SubjectPositionSettlement, `{"fundingTime":1551770400000,"qty":100,"markPrice":3610.85,"fundingRate":-0.002966,"fundingFee":-296,"ts":1547697294838004923,"settleCurrency":"XBT"}`)
+	settlement := <-positions
+	if settlement.Subject != kugo.SubjectPositionSettlement || settlement.Symbol != "XBTUSDTM" || settlement.Qty != 100 || settlement.FundingFee.String() != "-296" {
+		t.Fatalf("unexpected settlement event %+v", settlement)
+	}
+
+	s.push("/contractAccount/wallet", kugo.SubjectAvailableBalance, `{"availableBalance":5923,"holdBalance":2312,"currency":"USDT","timestamp":1553842862614}`)
+	wallet := <-wallets
+	if wallet.Subject != kugo.SubjectAvailableBalance || wallet.AvailableBalance.String() != "5923" || wallet.HoldBalance.String() != "2312" {
+		t.Fatalf("unexpected wallet event %+v", wallet)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"github.com/xiiiew/kugo"
+	"testing"
+)
+
+func TestWsSpotMarket(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	tickers := make(chan *kugo.SpotTickerEvent, 10)
+	if err := ws.SubscribeSpotTicker(func(e *kugo.SpotTickerEvent) { tickers <- e }, "all"); err != nil {
+		t.Fatal(err)
+	}
+	level2 := make(chan *kugo.SpotLevel2Event, 10)
+	if err := ws.SubscribeSpotLevel2(func(e *kugo.SpotLevel2Event) { level2 <- e }, "BTC-USDT"); err != nil {
+		t.Fatal(err)
+	}
+	candles := make(chan *kugo.SpotCandleEvent, 10)
+	if err := ws.SubscribeSpotCandles(func(e *kugo.SpotCandleEvent) { candles <- e }, "BTC-USDT", "1hour"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.push("/market/ticker:all", "BTC-USDT", `{"bestAsk":"20000.1","bestAskSize":"0.5","bestBid":"20000","bestBidSize":"1.2","price":"20000","sequence":"1545896668986","size":"0.011","time":1545904567062}`)
+	ticker := <-tickers
+	if ticker.Symbol != "BTC-USDT" || ticker.Sequence != 1545896668986 || ticker.BestAsk.String() != "20000.1" {
+		t.Fatalf("unexpected ticker %+v", ticker)
+	}
+
+	s.push("/market/level2:BTC-USDT", "trade.l2update", `{"changes":{"asks":[["18906","0.00331","14103845"],["18907.3","0.58751503","14103844"]],"bids":[["18891.9","0.15688","14103847"]]},"sequenceEnd":14103847,"sequenceStart":14103844,"symbol":"BTC-USDT","time":1663747970273}`)
+	l2 := <-level2
+	if len(l2.Changes.Asks) != 2 || l2.Changes.Asks[1].Sequence != 14103844 || l2.Changes.Bids[0].Price.String() != "18891.9" {
+		t.Fatalf("unexpected level2 %+v", l2)
+	}
+
+	s.push("/market/candles:BTC-USDT_1hour", "trade.candles.update", `{"symbol":"BTC-USDT","candles":["1589968800","9786.9","9740.8","9806.1","9732","27.45649579","268280.09830877"],"time":1589970010253893337}`)
+	candle := <-candles
+	if candle.Candles.Time != 1589968800 || candle.Candles.Close.String() != "9740.8" || candle.Candles.Turnover.String() != "268280.09830877" {
+		t.Fatalf("unexpected candle %+v", candle)
+	}
+}
+
+func TestWsSpotPrivate(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewSpotWsClient(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := make(chan *kugo.SpotOrderEvent, 10)
+	if err = ws.SubscribeSpotOrdersV2(func(e *kugo.SpotOrderEvent) { orders <- e }); err != nil {
+		t.Fatal(err)
+	}
+	balances := make(chan *kugo.SpotBalanceEvent, 10)
+	if err = ws.SubscribeSpotBalance(func(e *kugo.SpotBalanceEvent) { balances <- e }); err != nil {
+		t.Fatal(err)
+	}
+	positions := make(chan *kugo.SpotMarginPositionEvent, 10)
+	if err = ws.SubscribeSpotMarginPosition(func(e *kugo.SpotMarginPositionEvent) { positions <- e }); err != nil {
+		t.Fatal(err)
+	}
+
+	s.push("/spotMarket/tradeOrdersV2", "orderChange", `{"symbol":"KCS-USDT","orderType":"limit","side":"sell","orderId":"5efab07953bdea00089e2a7b","liquidity":"taker","type":"match","orderTime":1670329987026,"size":"0.1","filledSize":"0.1","price":"0.937","matchPrice":"0.937","matchSize":"0.1","tradeId":"5efab07a4ee4c7000a82d6d9","clientOid":"1593487481000313","remainSize":"0","status":"match","ts":1670329987311000000}`)
+	order := <-orders
+	if order.Type != kugo.OrderEventMatch || order.MatchSize.String() != "0.1" || order.ClientOid != "1593487481000313" {
+		t.Fatalf("unexpected order event %+v", order)
+	}
+
+	s.push("/account/balance", "account.balance", `{"total":"88","available":"88","availableChange":"88","currency":"KCS","hold":"0","holdChange":"0","relationEvent":"trade.setted","relationEventId":"5c21e80303aa677bd09d7dff","relationContext":{"symbol":"BTC-USDT","tradeId":"5e6a5dca9e16882a7d83b7a4","orderId":"5ea10479415e2f0009949d54"},"time":"1545743136994"}`)
+	balance := <-balances
+	if balance.Currency != "KCS" || balance.Total.String() != "88" || balance.Time != 1545743136994 || balance.RelationContext.OrderId != "5ea10479415e2f0009949d54" {
+		t.Fatalf("unexpected balance event %+v", balance)
+	}
+
+	s.push("/margin/position", "debt.ratio", `{"debtRatio":0.7505,"totalDebt":"21.7505","debtList":{"BTC":"1.21","USDT":"2121.2121"},"timestamp":15538460812100}`)
+	position := <-positions
+	if position.Subject != "debt.ratio" || position.DebtRatio.String() != "0.7505" || position.DebtList["USDT"].String() != "2121.2121" {
+		t.Fatalf("unexpected margin position event %+v", position)
+	}
+}
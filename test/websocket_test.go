@@ -0,0 +1,383 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/xiiiew/kugo"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeServer An in-process stand-in of the KuCoin REST and WebSocket servers
+type fakeServer struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	tokens     int32 // Number of tokens applied
+	noPong     int32 // Do not reply to ping messages if it is not 0
+	mu         sync.Mutex
+	conns      []*websocket.Conn
+	subscribes chan string
+	tunnels    chan string // Opened tunnels
+}
+
+func newFakeServer() *fakeServer {
+	s := &fakeServer{
+		mux:        http.NewServeMux(),
+		subscribes: make(chan string, 1024),
+		tunnels:    make(chan string, 1024),
+	}
+	bullet := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := json.Marshal(map[string]interface{}{"code": "200000", "data": s.bullet()})
+		w.Write(b)
+	}
+	s.mux.HandleFunc(kugo.UriSpotBulletPublic, bullet)
+	s.mux.HandleFunc(kugo.UriSpotBulletPrivate, bullet)
+	s.mux.Handle("/endpoint", websocket.Handler(s.serveWs))
+	s.Server = httptest.NewServer(s.mux)
+	return s
+}
+
+func (s *fakeServer) bullet() *kugo.BulletData {
+	n := atomic.AddInt32(&s.tokens, 1)
+	return &kugo.BulletData{
+		Token: fmt.Sprintf("token%d", n),
+		InstanceServers: []kugo.InstanceServerData{{
+			Endpoint:     "ws" + strings.TrimPrefix(s.URL, "http") + "/endpoint",
+			Protocol:     "websocket",
+			PingInterval: 50,
+			PingTimeout:  50,
+		}},
+	}
+}
+
+func (s *fakeServer) serveWs(ws *websocket.Conn) {
+	if err := websocket.JSON.Send(ws, &kugo.WsMessage{Id: ws.Request().URL.Query().Get("connectId"), Type: kugo.WsTypeWelcome}); err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.conns = append(s.conns, ws)
+	s.mu.Unlock()
+
+	for {
+		msg := &kugo.WsMessage{}
+		if err := websocket.JSON.Receive(ws, msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case kugo.WsTypePing:
+			if atomic.LoadInt32(&s.noPong) == 0 {
+				websocket.JSON.Send(ws, &kugo.WsMessage{Id: msg.Id, Type: kugo.WsTypePong})
+			}
+		case kugo.WsTypeSubscribe:
+			if strings.Contains(msg.Topic, "invalid") {
+				websocket.JSON.Send(ws, &kugo.WsMessage{Id: msg.Id, Type: kugo.WsTypeError, Code: 404, Data: json.RawMessage(`"topic not found"`)})
+				continue
+			}
+			websocket.JSON.Send(ws, &kugo.WsMessage{Id: msg.Id, Type: kugo.WsTypeAck})
+			s.subscribes <- msg.Topic
+		case kugo.WsTypeUnsubscribe, kugo.WsTypeCloseTunnel:
+			websocket.JSON.Send(ws, &kugo.WsMessage{Id: msg.Id, Type: kugo.WsTypeAck})
+		case kugo.WsTypeOpenTunnel:
+			websocket.JSON.Send(ws, &kugo.WsMessage{Id: msg.Id, Type: kugo.WsTypeAck})
+			s.tunnels <- msg.NewTunnelId
+		}
+	}
+}
+
+// push Send a message to every connection
+func (s *fakeServer) push(topic, subject, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ws := range s.conns {
+		websocket.JSON.Send(ws, &kugo.WsMessage{Type: kugo.WsTypeMessage, Topic: topic, Subject: subject, Data: json.RawMessage(data)})
+	}
+}
+
+// drop Close every connection
+func (s *fakeServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ws := range s.conns {
+		ws.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeServer) waitSubscribe(t *testing.T, topic string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-s.subscribes:
+			if got == topic {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("topic %s is not subscribed", topic)
+		}
+	}
+}
+
+func (s *fakeServer) kucoin(t *testing.T) *kugo.Kucoin {
+	t.Helper()
+	i, err := kugo.NewKucoin(
+		kugo.SetSpotEndpoint(s.URL),
+		kugo.SetFutureEndpoint(s.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func newTestWsClient(t *testing.T, s *fakeServer) *kugo.WsClient {
+	t.Helper()
+	ws, err := s.kucoin(t).NewSpotWsClient(false,
+		kugo.SetWsReconnectBackoff(10*time.Millisecond, 50*time.Millisecond),
+		kugo.SetWsAckTimeout(time.Second),
+		kugo.SetWsErrorLog(func(i ...interface{}) { t.Log(i...) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func receive(t *testing.T, ch <-chan *kugo.WsMessage) *kugo.WsMessage {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestWsClientSubscribe(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+
+	ch := make(chan *kugo.WsMessage, 10)
+	if err := ws.Subscribe("/market/ticker:BTC-USDT,ETH-USDT", false, func(msg *kugo.WsMessage) { ch <- msg }); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.waitSubscribe(t, "/market/ticker:BTC-USDT,ETH-USDT")
+
+	s.push("/market/ticker:BTC-USDT", "trade.ticker", `{"price":"20000"}`)
+	s.push("/market/ticker:ETH-USDT", "trade.ticker", `{"price":"1500"}`)
+	s.push("/market/ticker:XRP-USDT", "trade.ticker", `{"price":"0.5"}`)
+	if msg := receive(t, ch); msg.Topic != "/market/ticker:BTC-USDT" {
+		t.Fatalf("unexpected topic %s", msg.Topic)
+	}
+	if msg := receive(t, ch); msg.Topic != "/market/ticker:ETH-USDT" {
+		t.Fatalf("unexpected topic %s", msg.Topic)
+	}
+
+	if err := ws.Subscribe("/market/invalid:BTC-USDT", false, func(msg *kugo.WsMessage) {}); err == nil {
+		t.Fatal("subscribing an invalid topic should fail")
+	}
+	if topics := ws.Topics(); len(topics) != 1 {
+		t.Fatalf("unexpected topics %v", topics)
+	}
+
+	if err := ws.Unsubscribe("/market/ticker:BTC-USDT,ETH-USDT"); err != nil {
+		t.Fatal(err)
+	}
+	s.push("/market/ticker:BTC-USDT", "trade.ticker", `{"price":"20000"}`)
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected message after unsubscribing: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWsClientReconnect(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+
+	ch := make(chan *kugo.WsMessage, 10)
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Subscribe("/market/match:BTC-USDT", false, func(msg *kugo.WsMessage) { ch <- msg }); err != nil {
+		t.Fatal(err)
+	}
+	s.waitSubscribe(t, "/market/match:BTC-USDT")
+
+	s.drop()
+	s.waitSubscribe(t, "/market/match:BTC-USDT")
+	if n := atomic.LoadInt32(&s.tokens); n != 2 {
+		t.Fatalf("want a new token for reconnecting, %d tokens applied", n)
+	}
+	s.push("/market/match:BTC-USDT", "trade.l3match", `{"price":"20000"}`)
+	receive(t, ch)
+}
+
+func TestWsClientPingTimeout(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws := newTestWsClient(t, s)
+	defer ws.Close()
+
+	if err := ws.Subscribe("/market/match:BTC-USDT", false, func(msg *kugo.WsMessage) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.waitSubscribe(t, "/market/match:BTC-USDT")
+
+	// The connection is kept while the server replies to ping
+	time.Sleep(300 * time.Millisecond)
+	if n := atomic.LoadInt32(&s.tokens); n != 1 {
+		t.Fatalf("unexpected reconnection, %d tokens applied", n)
+	}
+
+	atomic.StoreInt32(&s.noPong, 1)
+	s.waitSubscribe(t, "/market/match:BTC-USDT")
+	if n := atomic.LoadInt32(&s.tokens); n < 2 {
+		t.Fatalf("want a reconnection after ping timeout, %d tokens applied", n)
+	}
+}
+
+func TestWsClientSharding(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewSpotWsClient(false, kugo.SetWsMaxTopics(2), kugo.SetWsAckTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan *kugo.WsMessage, 10)
+	if err = ws.Subscribe("/market/ticker:A-USDT,B-USDT,C-USDT,D-USDT,E-USDT", false, func(msg *kugo.WsMessage) { ch <- msg }); err != nil {
+		t.Fatal(err)
+	}
+	stats := ws.Stats()
+	if len(stats) != 3 || stats[0].Topics != 2 || stats[1].Topics != 2 || stats[2].Topics != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	for _, stat := range stats {
+		if !stat.Connected || stat.ConnectId == "" {
+			t.Fatalf("unexpected stats %+v", stat)
+		}
+	}
+	if n := atomic.LoadInt32(&s.tokens); n != 3 {
+		t.Fatalf("want 3 connections, %d tokens applied", n)
+	}
+
+	// Messages of every connection are passed to the same handler
+	s.push("/market/ticker:A-USDT", "trade.ticker", `{"price":"1"}`)
+	s.push("/market/ticker:E-USDT", "trade.ticker", `{"price":"1"}`)
+	receive(t, ch)
+	receive(t, ch)
+	waitUntil(t, "message stats", func() bool {
+		var messages int64
+		for _, stat := range ws.Stats() {
+			messages += stat.Messages
+		}
+		return messages == 6 // Every connection receives both messages
+	})
+
+	// Connections without topics are closed
+	if err = ws.Unsubscribe("/market/ticker:A-USDT,B-USDT,C-USDT,D-USDT,E-USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if stats = ws.Stats(); len(stats) != 1 || stats[0].Topics != 0 {
+		t.Fatalf("unexpected stats after unsubscribing %+v", stats)
+	}
+}
+
+func TestWsClientFanIn(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewSpotWsClient(false, kugo.SetWsMaxTopics(1), kugo.SetWsAckTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	var running, overlaps, handled int32
+	if err = ws.Subscribe("/market/ticker:A-USDT,B-USDT", false, func(msg *kugo.WsMessage) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&handled, 1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := ws.Stats(); len(stats) != 2 {
+		t.Fatalf("want 2 connections, got %+v", stats)
+	}
+
+	// Messages of both connections are passed to the handler one at a time
+	for i := 0; i < 10; i++ {
+		s.push("/market/ticker:A-USDT", "trade.ticker", `{"price":"1"}`)
+		s.push("/market/ticker:B-USDT", "trade.ticker", `{"price":"1"}`)
+	}
+	waitUntil(t, "messages handled", func() bool { return atomic.LoadInt32(&handled) == 40 })
+	if n := atomic.LoadInt32(&overlaps); n != 0 {
+		t.Fatalf("the handler is called concurrently %d times", n)
+	}
+}
+
+func TestWsClientTunnels(t *testing.T) {
+	s := newFakeServer()
+	defer s.Close()
+	ws, err := s.kucoin(t).NewSpotWsClient(false, kugo.SetWsMaxTopics(2), kugo.SetWsTunnels(2), kugo.SetWsAckTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// Subscribed on Connect
+	if err = ws.Subscribe("/market/match:A-USDT,B-USDT,C-USDT,D-USDT,E-USDT", false, func(msg *kugo.WsMessage) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err = ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	stats := ws.Stats()
+	if len(stats) != 2 || len(stats[0].Tunnels) != 2 || len(stats[1].Tunnels) != 1 || stats[0].Topics != 4 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-s.tunnels:
+		case <-time.After(5 * time.Second):
+			t.Fatal("tunnel is not opened")
+		}
+	}
+	s.waitSubscribe(t, "/market/match:E-USDT")
+
+	// Tunnels are opened again after reconnecting
+	s.drop()
+	waitUntil(t, "reconnection", func() bool {
+		stats := ws.Stats()
+		return len(stats) == 2 && stats[0].Reconnects == 1 && stats[1].Reconnects == 1 && stats[0].Connected && stats[1].Connected
+	})
+	waitUntil(t, "tunnels opened again", func() bool { return len(s.tunnels) == 3 })
+}
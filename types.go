@@ -1,24 +1,70 @@
 package kugo
 
-import "github.com/shopspring/decimal"
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strconv"
+)
 
 // URI
 const (
-	UriSpotSymbols     = "/api/v2/symbols"
-	UriSpotAccount     = "/api/v1/accounts"
-	UriSpotOrders      = "/api/v1/orders"
-	UriSpotMarginOrder = "/api/v1/margin/order"
-	UriSpotOrderFills  = "/api/v1/fills"
-	UriSpotOrderCancel = "/api/v1/orders/%s"
-	UriSpotOrderOne    = "/api/v1/orders/%s"
-
-	UriFutureAccount     = "/api/v1/account-overview"
-	UriFutureOrders      = "/api/v1/orders"
-	UriFutureOrderCancel = "/api/v1/orders/%s"
-	UriFutureOrderOne    = "/api/v1/orders/%s"
-	UriFutureOrderFills  = "/api/v1/fills"
-	UriFuturePosition    = "/api/v1/position"
-	UriFutureSymbols     = "/api/v1/contracts/active"
+	UriSpotSymbols         = "/api/v2/symbols"
+	UriSpotAccount         = "/api/v1/accounts"
+	UriSpotOrders          = "/api/v1/orders"
+	UriSpotMarginOrder     = "/api/v1/margin/order"
+	UriSpotOrderFills      = "/api/v1/fills"
+	UriSpotOrderCancel     = "/api/v1/orders/%s"
+	UriSpotOrderOne        = "/api/v1/orders/%s"
+	UriSpotOrderByClient   = "/api/v1/order/client-order/%s"
+	UriSpotCurrencies      = "/api/v3/currencies"
+	UriSpotCurrency        = "/api/v3/currencies/%s"
+	UriSpotPrices          = "/api/v1/prices"
+	UriSpotBaseFee         = "/api/v1/base-fee"
+	UriSpotTradeFees       = "/api/v1/trade-fees"
+	UriSpotStatus          = "/api/v1/status"
+	UriSpotBulletPublic    = "/api/v1/bullet-public"
+	UriSpotBulletPrivate   = "/api/v1/bullet-private"
+	UriSpotOrderBook       = "/api/v3/market/orderbook/level2"
+	UriSpotOrderBookPart   = "/api/v1/market/orderbook/level2_%d"
+	UriSpotOrderBookLevel3 = "/api/v3/market/orderbook/level3"
+	UriSpotKlines          = "/api/v1/market/candles"
+	UriSpotTicker          = "/api/v1/market/orderbook/level1"
+	UriSpotLedgers         = "/api/v1/accounts/ledgers"
+	UriSpotMarginAccount   = "/api/v1/margin/account"
+	UriSpotIsolatedAccount = "/api/v1/isolated/accounts"
+	UriSpotAllTickers      = "/api/v1/market/allTickers"
+
+	UriFutureAccount       = "/api/v1/account-overview"
+	UriFutureOrders        = "/api/v1/orders"
+	UriFutureOrderCancel   = "/api/v1/orders/%s"
+	UriFutureOrderOne      = "/api/v1/orders/%s"
+	UriFutureOrderByClient = "/api/v1/orders/byClientOid"
+	UriFutureOrderFills    = "/api/v1/fills"
+	UriFuturePosition      = "/api/v1/position"
+	UriFutureSymbols       = "/api/v1/contracts/active"
+	UriFutureTradeFee      = "/api/v1/trade-fees"
+	UriFutureStatus        = "/api/v1/status"
+	UriFutureBulletPublic  = "/api/v1/bullet-public"
+	UriFutureBulletPrivate = "/api/v1/bullet-private"
+	UriFutureOrderBook     = "/api/v1/level2/snapshot"
+	UriFutureKlines        = "/api/v1/kline/query"
+
+	UriSubUserList        = "/api/v2/sub/user"
+	UriSubAccountOne      = "/api/v1/sub-accounts/%s"
+	UriSubAccountList     = "/api/v1/sub-accounts"
+	UriSubAccountListV2   = "/api/v2/sub-accounts"
+	UriSubApiKey          = "/api/v1/sub/api-key"
+	UriSubApiKeyUpdate    = "/api/v1/sub/api-key/update"
+	UriSubAccountTransfer = "/api/v2/accounts/sub-transfer"
+
+	UriDepositAddress   = "/api/v1/deposit-addresses"
+	UriDepositAddressV2 = "/api/v2/deposit-addresses"
+	UriDepositAddressV3 = "/api/v3/deposit-addresses"
+	UriDepositList      = "/api/v1/deposits"
+	UriWithdrawalQuotas = "/api/v1/withdrawals/quotas"
+	UriWithdrawals      = "/api/v1/withdrawals"
+	UriWithdrawalCancel = "/api/v1/withdrawals/%s"
 )
 
 type BaseResponse struct {
@@ -32,6 +78,130 @@ type BaseResponsePagination struct {
 	TotalPage   int `json:"totalPage"`
 }
 
+// Side Side of an order
+type Side string
+
+const (
+	SideBuy  Side = "buy"
+	SideSell Side = "sell"
+)
+
+// OrderType Type of an order
+type OrderType string
+
+const (
+	OrderTypeLimit  OrderType = "limit"
+	OrderTypeMarket OrderType = "market"
+)
+
+// TimeInForce Time in force of a limit order
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC" // Good till canceled
+	TimeInForceGTT TimeInForce = "GTT" // Good till time, canceled after CancelAfter seconds
+	TimeInForceIOC TimeInForce = "IOC" // Immediate or cancel
+	TimeInForceFOK TimeInForce = "FOK" // Fill or kill
+)
+
+// STP Self trade prevention
+type STP string
+
+const (
+	STPCancelNewest STP = "CN" // Cancel newest
+	STPCancelOldest STP = "CO" // Cancel oldest
+	STPCancelBoth   STP = "CB" // Cancel both
+	STPDecrease     STP = "DC" // Decrease and cancel
+)
+
+// TradeType Type of a spot trade
+type TradeType string
+
+const (
+	TradeTypeSpot           TradeType = "TRADE"
+	TradeTypeMargin         TradeType = "MARGIN_TRADE"
+	TradeTypeIsolatedMargin TradeType = "MARGIN_ISOLATED_TRADE"
+)
+
+// MarginMode Mode of a margin order
+type MarginMode string
+
+const (
+	MarginModeCross    MarginMode = "cross"
+	MarginModeIsolated MarginMode = "isolated"
+)
+
+// StopPriceType Price which triggers a stop order
+type StopPriceType string
+
+const (
+	StopPriceTypeTrade StopPriceType = "TP" // Trade price
+	StopPriceTypeIndex StopPriceType = "IP" // Index price
+	StopPriceTypeMark  StopPriceType = "MP" // Mark price
+)
+
+// StopDirection Direction of the price which triggers a future stop order
+type StopDirection string
+
+const (
+	StopDown StopDirection = "down" // Triggered when the price is lower than or equal to the stop price
+	StopUp   StopDirection = "up"   // Triggered when the price is higher than or equal to the stop price
+)
+
+// Service status
+const (
+	ServiceStatusOpen       = "open"
+	ServiceStatusClose      = "close"
+	ServiceStatusCancelOnly = "cancelonly"
+)
+
+// SpotOrderBookResponse Response of GET /api/v3/market/orderbook/level2 and GET /api/v1/market/orderbook/level2_{depth}
+type SpotOrderBookResponse struct {
+	BaseResponse
+	Data SpotOrderBookData `json:"data"`
+}
+type SpotOrderBookData struct {
+	Sequence int64            `json:"sequence,string"`
+	Time     int64            `json:"time"` // millisecond
+	Bids     []OrderBookLevel `json:"bids"`
+	Asks     []OrderBookLevel `json:"asks"`
+}
+
+// SpotOrderBookLevel3Response Response of GET /api/v3/market/orderbook/level3
+type SpotOrderBookLevel3Response struct {
+	BaseResponse
+	Data SpotOrderBookLevel3Data `json:"data"`
+}
+type SpotOrderBookLevel3Data struct {
+	Sequence int64            `json:"sequence"`
+	Time     int64            `json:"time"` // millisecond
+	Bids     []OrderBookOrder `json:"bids"`
+	Asks     []OrderBookOrder `json:"asks"`
+}
+
+// FutureOrderBookResponse Response of GET /api/v1/level2/snapshot
+type FutureOrderBookResponse struct {
+	BaseResponse
+	Data FutureOrderBookData `json:"data"`
+}
+type FutureOrderBookData struct {
+	Symbol   string           `json:"symbol"`
+	Sequence int64            `json:"sequence"`
+	Bids     []OrderBookLevel `json:"bids"`
+	Asks     []OrderBookLevel `json:"asks"`
+	Ts       int64            `json:"ts"` // nanosecond
+}
+
+// ServiceStatusResponse Response of GET /api/v1/status
+type ServiceStatusResponse struct {
+	BaseResponse
+	Data ServiceStatusData `json:"data"`
+}
+type ServiceStatusData struct {
+	Status string `json:"status"` // open, close or cancelonly
+	Msg    string `json:"msg"`    // Remark for operation
+}
+
 // SymbolsResponse Response of GET /api/v2/symbols
 type SymbolsResponse struct {
 	BaseResponse
@@ -57,6 +227,51 @@ type SymbolsData struct {
 	EnableTrading   bool            `json:"enableTrading"`
 }
 
+// CurrenciesResponse Response of GET /api/v3/currencies
+type CurrenciesResponse struct {
+	BaseResponse
+	Data []CurrencyData `json:"data"`
+}
+
+// CurrencyResponse Response of GET /api/v3/currencies/{currency}
+type CurrencyResponse struct {
+	BaseResponse
+	Data CurrencyData `json:"data"`
+}
+type CurrencyData struct {
+	Currency        string              `json:"currency"`
+	Name            string              `json:"name"`
+	FullName        string              `json:"fullName"`
+	Precision       int32               `json:"precision"` // Number of decimal places
+	Confirms        int                 `json:"confirms"`
+	ContractAddress string              `json:"contractAddress"`
+	IsMarginEnabled bool                `json:"isMarginEnabled"`
+	IsDebitEnabled  bool                `json:"isDebitEnabled"`
+	Chains          []CurrencyChainData `json:"chains"`
+}
+type CurrencyChainData struct {
+	ChainName         string          `json:"chainName"`
+	ChainId           string          `json:"chainId"`
+	WithdrawalMinSize decimal.Decimal `json:"withdrawalMinSize"`
+	WithdrawalMinFee  decimal.Decimal `json:"withdrawalMinFee"`
+	WithdrawFeeRate   decimal.Decimal `json:"withdrawFeeRate"`
+	DepositMinSize    decimal.Decimal `json:"depositMinSize"`
+	MaxWithdraw       decimal.Decimal `json:"maxWithdraw"`
+	MaxDeposit        decimal.Decimal `json:"maxDeposit"`
+	IsWithdrawEnabled bool            `json:"isWithdrawEnabled"`
+	IsDepositEnabled  bool            `json:"isDepositEnabled"`
+	Confirms          int             `json:"confirms"`    // Number of blocks for the deposit to be credited
+	PreConfirms       int             `json:"preConfirms"` // Number of blocks for the deposit to be available for trading
+	ContractAddress   string          `json:"contractAddress"`
+	NeedTag           bool            `json:"needTag"` // Whether a memo/tag is required
+}
+
+// PricesResponse Response of GET /api/v1/prices
+type PricesResponse struct {
+	BaseResponse
+	Data map[string]decimal.Decimal `json:"data"`
+}
+
 // AccountsResponse Response of GET /api/v2/accounts
 type AccountsResponse struct {
 	BaseResponse
@@ -71,18 +286,121 @@ type AccountsData struct {
 	Holds     decimal.Decimal `json:"holds"`
 }
 
+// SpotLedgersRequest Request of GET /api/v1/accounts/ledgers
+type SpotLedgersRequest struct {
+	Currency  string `json:"currency,omitempty"`  // Up to 10 currencies separated by commas
+	Direction string `json:"direction,omitempty"` // in or out
+	BizType   string `json:"bizType,omitempty"`   // e.g. DEPOSIT, WITHDRAW, TRANSFER, TRADE_EXCHANGE
+	StartAt   int64  `json:"startAt,omitempty"`   // Start time (millisecond)
+	EndAt     int64  `json:"endAt,omitempty"`     // End time (millisecond)
+}
+
+// SpotLedgersResponse Response of GET /api/v1/accounts/ledgers
+type SpotLedgersResponse struct {
+	BaseResponse
+	Data SpotLedgersData `json:"data"`
+}
+type SpotLedgersData struct {
+	BaseResponsePagination
+	Items []LedgerItem `json:"items"`
+}
+type LedgerItem struct {
+	Id          string          `json:"id"`
+	Currency    string          `json:"currency"`
+	Amount      decimal.Decimal `json:"amount"`
+	Fee         decimal.Decimal `json:"fee"`
+	Balance     decimal.Decimal `json:"balance"`
+	AccountType string          `json:"accountType"` // MAIN, TRADE, MARGIN or CONTRACT
+	BizType     string          `json:"bizType"`
+	Direction   string          `json:"direction"` // in or out
+	CreatedAt   int64           `json:"createdAt"`
+	Context     string          `json:"context"` // JSON of the business, e.g. the orderId and tradeId of a trade
+}
+
+// SpotMarginAccountResponse Response of GET /api/v1/margin/account
+type SpotMarginAccountResponse struct {
+	BaseResponse
+	Data SpotMarginAccountData `json:"data"`
+}
+type SpotMarginAccountData struct {
+	DebtRatio decimal.Decimal     `json:"debtRatio"`
+	Accounts  []MarginAccountItem `json:"accounts"`
+}
+type MarginAccountItem struct {
+	Currency         string          `json:"currency"`
+	TotalBalance     decimal.Decimal `json:"totalBalance"`
+	AvailableBalance decimal.Decimal `json:"availableBalance"`
+	HoldBalance      decimal.Decimal `json:"holdBalance"`
+	Liability        decimal.Decimal `json:"liability"` // Borrowed amount and interest to repay
+	MaxBorrowSize    decimal.Decimal `json:"maxBorrowSize"`
+}
+
+// SpotIsolatedAccountsResponse Response of GET /api/v1/isolated/accounts
+type SpotIsolatedAccountsResponse struct {
+	BaseResponse
+	Data SpotIsolatedAccountsData `json:"data"`
+}
+type SpotIsolatedAccountsData struct {
+	TotalConversionBalance     decimal.Decimal       `json:"totalConversionBalance"`     // In the balance currency
+	LiabilityConversionBalance decimal.Decimal       `json:"liabilityConversionBalance"` // In the balance currency
+	Assets                     []IsolatedAccountItem `json:"assets"`
+}
+type IsolatedAccountItem struct {
+	Symbol     string          `json:"symbol"`
+	Status     string          `json:"status"` // e.g. CLEAR, DEBT, BORROWING, REPAY, IN_BORROW, IN_REPAY
+	DebtRatio  decimal.Decimal `json:"debtRatio"`
+	BaseAsset  IsolatedAsset   `json:"baseAsset"`
+	QuoteAsset IsolatedAsset   `json:"quoteAsset"`
+}
+type IsolatedAsset struct {
+	Currency         string          `json:"currency"`
+	TotalBalance     decimal.Decimal `json:"totalBalance"`
+	HoldBalance      decimal.Decimal `json:"holdBalance"`
+	AvailableBalance decimal.Decimal `json:"availableBalance"`
+	Liability        decimal.Decimal `json:"liability"`
+	Interest         decimal.Decimal `json:"interest"`
+	BorrowableAmount decimal.Decimal `json:"borrowableAmount"`
+}
+
+// SpotBaseFeeResponse Response of GET /api/v1/base-fee
+type SpotBaseFeeResponse struct {
+	BaseResponse
+	Data SpotBaseFeeData `json:"data"`
+}
+type SpotBaseFeeData struct {
+	TakerFeeRate decimal.Decimal `json:"takerFeeRate"`
+	MakerFeeRate decimal.Decimal `json:"makerFeeRate"`
+}
+
+// SpotTradeFeesResponse Response of GET /api/v1/trade-fees
+type SpotTradeFeesResponse struct {
+	BaseResponse
+	Data []TradeFeeData `json:"data"`
+}
+
+// FutureTradeFeeResponse Response of GET /api/v1/trade-fees
+type FutureTradeFeeResponse struct {
+	BaseResponse
+	Data TradeFeeData `json:"data"`
+}
+type TradeFeeData struct {
+	Symbol       string          `json:"symbol"`
+	TakerFeeRate decimal.Decimal `json:"takerFeeRate"`
+	MakerFeeRate decimal.Decimal `json:"makerFeeRate"`
+}
+
 // SpotOrdersRequest Request of POST /api/v1/orders
 type SpotOrdersRequest struct {
 	ClientOid   string          `json:"clientOid,omitempty"`
-	Side        string          `json:"side,omitempty"`   // buy or sell
+	Side        Side            `json:"side,omitempty"`   // buy or sell
 	Symbol      string          `json:"symbol,omitempty"` // e.g. BTC-USDT
-	Type        string          `json:"type,omitempty"`   // limit or market
+	Type        OrderType       `json:"type,omitempty"`   // limit or market
 	Remark      string          `json:"remark,omitempty"`
-	Stp         string          `json:"stp,omitempty"`
-	TradeType   string          `json:"tradeType,omitempty"`
+	Stp         STP             `json:"stp,omitempty"`
+	TradeType   TradeType       `json:"tradeType,omitempty"`
 	Price       decimal.Decimal `json:"price,omitempty"`
 	Size        decimal.Decimal `json:"size,omitempty"`
-	TimeInForce string          `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
+	TimeInForce TimeInForce     `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
 	CancelAfter int64           `json:"cancelAfter,omitempty"`
 	PostOnly    bool            `json:"postOnly,omitempty"`
 	Hidden      bool            `json:"hidden,omitempty"`
@@ -91,28 +409,47 @@ type SpotOrdersRequest struct {
 	Funds       string          `json:"funds,omitempty"` // MARKET order only, It is required that you use one of the two parameters, size or funds.
 }
 
+// MarshalJSON Omit the zero price and size, e.g. of a market order by funds
+func (r SpotOrdersRequest) MarshalJSON() ([]byte, error) {
+	type request SpotOrdersRequest
+	return json.Marshal(struct {
+		request
+		Price string `json:"price,omitempty"`
+		Size  string `json:"size,omitempty"`
+	}{request(r), nonZeroDecimal(r.Price), nonZeroDecimal(r.Size)})
+}
+
+// nonZeroDecimal Return the decimal as a string, or an empty string if it is zero
+func nonZeroDecimal(d decimal.Decimal) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.String()
+}
+
 // SpotOrderResponse Response of POST /api/v1/orders
 type SpotOrderResponse struct {
 	BaseResponse
 	Data SpotOrderData `json:"data"`
 }
 type SpotOrderData struct {
-	OrderId string `json:"orderId"`
+	OrderId   string `json:"orderId"`
+	ClientOid string `json:"clientOid"` // The clientOid of the request, set by the client
 }
 
 // SpotMarginOrderRequest Request of POST /api/v1/margin/order
 type SpotMarginOrderRequest struct {
 	ClientOid   string          `json:"clientOid,omitempty"`
-	Side        string          `json:"side,omitempty"`   // buy or sell
+	Side        Side            `json:"side,omitempty"`   // buy or sell
 	Symbol      string          `json:"symbol,omitempty"` // e.g. BTC-USDT
-	Type        string          `json:"type,omitempty"`   // limit or market
+	Type        OrderType       `json:"type,omitempty"`   // limit or market
 	Remark      string          `json:"remark,omitempty"`
-	Stp         string          `json:"stp,omitempty"` // CN, CO, CB or DC
-	MarginModel string          `json:"marginModel"`   // cross or isolated
+	Stp         STP             `json:"stp,omitempty"` // CN, CO, CB or DC
+	MarginModel MarginMode      `json:"marginModel"`   // cross or isolated
 	AutoBorrow  bool            `json:"autoBorrow"`
 	Price       decimal.Decimal `json:"price,omitempty"`
 	Size        decimal.Decimal `json:"size,omitempty"`
-	TimeInForce string          `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
+	TimeInForce TimeInForce     `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
 	CancelAfter int64           `json:"cancelAfter,omitempty"`
 	PostOnly    bool            `json:"postOnly,omitempty"`
 	Hidden      bool            `json:"hidden,omitempty"`
@@ -121,6 +458,16 @@ type SpotMarginOrderRequest struct {
 	Funds       string          `json:"funds,omitempty"` // MARKET order only, It is required that you use one of the two parameters, size or funds.
 }
 
+// MarshalJSON Omit the zero price and size, e.g. of a market order by funds
+func (r SpotMarginOrderRequest) MarshalJSON() ([]byte, error) {
+	type request SpotMarginOrderRequest
+	return json.Marshal(struct {
+		request
+		Price string `json:"price,omitempty"`
+		Size  string `json:"size,omitempty"`
+	}{request(r), nonZeroDecimal(r.Price), nonZeroDecimal(r.Size)})
+}
+
 // SpotMarginOrderResponse Response of POST /api/v1/margin/order
 type SpotMarginOrderResponse struct {
 	BaseResponse
@@ -130,6 +477,7 @@ type SpotMarginOrderData struct {
 	OrderId     string          `json:"orderId"`
 	BorrowSize  decimal.Decimal `json:"borrowSize"`
 	LoanApplyId string          `json:"loanApplyId"`
+	ClientOid   string          `json:"clientOid"` // The clientOid of the request, set by the client
 }
 
 // SpotOrderFillsRequest Request of GET /api/v1/fills
@@ -207,6 +555,13 @@ type SpotOrderOneResponse struct {
 	BaseResponse
 	Data SpotOrderOneData `json:"data"`
 }
+
+// SpotOrderClientOidResponse Response of GET /api/v1/order/client-order/{clientOid}
+type SpotOrderClientOidResponse struct {
+	BaseResponse
+	Data *SpotOrderOneData `json:"data"`
+}
+
 type SpotOrderOneData struct {
 	Id            string          `json:"id"`
 	Symbol        string          `json:"symbol"`
@@ -259,24 +614,36 @@ type FutureAccountData struct {
 // FutureOrderRequest Request of POST /api/v1/orders
 type FutureOrderRequest struct {
 	ClientOid     string          `json:"clientOid,omitempty"`
-	Side          string          `json:"side,omitempty"`   // buy or sell
+	Side          Side            `json:"side,omitempty"`   // buy or sell
 	Symbol        string          `json:"symbol,omitempty"` // e.g. BTC-USDT
-	Type          string          `json:"type,omitempty"`   // limit or market
+	Type          OrderType       `json:"type,omitempty"`   // limit or market
 	Leverage      decimal.Decimal `json:"leverage,omitempty"`
 	Remark        string          `json:"remark,omitempty"`
-	Stop          string          `json:"stop,omitempty"`
-	StopPriceType string          `json:"stopPriceType,omitempty"` // TP, IP or MP
+	Stop          StopDirection   `json:"stop,omitempty"`          // down or up
+	StopPriceType StopPriceType   `json:"stopPriceType,omitempty"` // TP, IP or MP
 	StopPrice     decimal.Decimal `json:"stopPrice,omitempty"`
 	ReduceOnly    bool            `json:"reduceOnly,omitempty"`
 	CloseOrder    bool            `json:"closeOrder,omitempty"`
 	ForceHold     bool            `json:"forceHold,omitempty"`
 	Price         decimal.Decimal `json:"price,omitempty"`
 	Size          int             `json:"size,omitempty"`        // Cont
-	TimeInForce   string          `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
+	TimeInForce   TimeInForce     `json:"timeInForce,omitempty"` // GTC, GTT, IOC or FOK
 	PostOnly      bool            `json:"postOnly,omitempty"`
 	Hidden        bool            `json:"hidden,omitempty"`
 	Iceberg       bool            `json:"iceberg,omitempty"`
 	VisibleSize   string          `json:"visibleSize,omitempty"`
+	Stp           STP             `json:"stp,omitempty"` // CN, CO or CB
+}
+
+// MarshalJSON Omit the zero leverage, stop price and price, e.g. of a market order
+func (r FutureOrderRequest) MarshalJSON() ([]byte, error) {
+	type request FutureOrderRequest
+	return json.Marshal(struct {
+		request
+		Leverage  string `json:"leverage,omitempty"`
+		StopPrice string `json:"stopPrice,omitempty"`
+		Price     string `json:"price,omitempty"`
+	}{request(r), nonZeroDecimal(r.Leverage), nonZeroDecimal(r.StopPrice), nonZeroDecimal(r.Price)})
 }
 
 // FutureOrderResponse Response of POST /api/v1/orders
@@ -285,7 +652,8 @@ type FutureOrderResponse struct {
 	Data FutureOrderData `json:"data"`
 }
 type FutureOrderData struct {
-	OrderId string `json:"orderId"`
+	OrderId   string `json:"orderId"`
+	ClientOid string `json:"clientOid"` // The clientOid of the request, set by the client
 }
 
 // FutureOrderCancelResponse Response of DELETE /api/v1/orders/{orderId}
@@ -322,6 +690,13 @@ type FutureOrderOneResponse struct {
 	BaseResponse
 	Data FutureOrderOneData `json:"data"`
 }
+
+// FutureOrderClientOidResponse Response of GET /api/v1/orders/byClientOid
+type FutureOrderClientOidResponse struct {
+	BaseResponse
+	Data *FutureOrderOneData `json:"data"`
+}
+
 type FutureOrderOneData struct {
 	Id             string          `json:"id"`
 	Symbol         string          `json:"symbol"`
@@ -510,3 +885,907 @@ type FutureSymbolData struct {
 	PriceChgPct             decimal.Decimal `json:"priceChgPct"`
 	PriceChg                decimal.Decimal `json:"priceChg"`
 }
+
+// SubUserListResponse Response of GET /api/v2/sub/user
+type SubUserListResponse struct {
+	BaseResponse
+	Data SubUserListData `json:"data"`
+}
+type SubUserListData struct {
+	BaseResponsePagination
+	Items []SubUserData `json:"items"`
+}
+type SubUserData struct {
+	UserId    string `json:"userId"`
+	Uid       int64  `json:"uid"`
+	SubName   string `json:"subName"`
+	Status    int    `json:"status"`
+	Type      int    `json:"type"`   // 0: normal, 1: robot, 2: nova, 3: master-sub
+	Access    string `json:"access"` // e.g. All, Spot, Futures, Margin
+	CreatedAt int64  `json:"createdAt"`
+	Remarks   string `json:"remarks"`
+}
+
+// SubAccountResponse Response of GET /api/v1/sub-accounts/{subUserId}
+type SubAccountResponse struct {
+	BaseResponse
+	Data SubAccountData `json:"data"`
+}
+
+// SubAccountListResponse Response of GET /api/v1/sub-accounts
+type SubAccountListResponse struct {
+	BaseResponse
+	Data []SubAccountData `json:"data"`
+}
+
+// SubAccountListV2Response Response of GET /api/v2/sub-accounts
+type SubAccountListV2Response struct {
+	BaseResponse
+	Data SubAccountListV2Data `json:"data"`
+}
+type SubAccountListV2Data struct {
+	BaseResponsePagination
+	Items []SubAccountData `json:"items"`
+}
+type SubAccountData struct {
+	SubUserId      string                  `json:"subUserId"`
+	SubName        string                  `json:"subName"`
+	MainAccounts   []SubAccountBalanceData `json:"mainAccounts"`
+	TradeAccounts  []SubAccountBalanceData `json:"tradeAccounts"`
+	MarginAccounts []SubAccountBalanceData `json:"marginAccounts"`
+}
+type SubAccountBalanceData struct {
+	Currency          string          `json:"currency"`
+	Balance           decimal.Decimal `json:"balance"`
+	Available         decimal.Decimal `json:"available"`
+	Holds             decimal.Decimal `json:"holds"`
+	BaseCurrency      string          `json:"baseCurrency"`
+	BaseCurrencyPrice decimal.Decimal `json:"baseCurrencyPrice"`
+	BaseAmount        decimal.Decimal `json:"baseAmount"`
+}
+
+// SubApiKeyListResponse Response of GET /api/v1/sub/api-key
+type SubApiKeyListResponse struct {
+	BaseResponse
+	Data []SubApiKeyData `json:"data"`
+}
+type SubApiKeyData struct {
+	SubName     string `json:"subName"`
+	Remark      string `json:"remark"`
+	ApiKey      string `json:"apiKey"`
+	ApiSecret   string `json:"apiSecret"`  // Only returned when the API key is created
+	Passphrase  string `json:"passphrase"` // Only returned when the API key is created
+	Permission  string `json:"permission"`
+	IpWhitelist string `json:"ipWhitelist"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// SubApiKeyCreateRequest Request of POST /api/v1/sub/api-key
+type SubApiKeyCreateRequest struct {
+	SubName     string `json:"subName"`
+	Passphrase  string `json:"passphrase"`            // 7~32 characters
+	Remark      string `json:"remark"`                // 1~24 characters
+	Permission  string `json:"permission,omitempty"`  // General, Trade, Futures (comma separated), General as default
+	IpWhitelist string `json:"ipWhitelist,omitempty"` // Up to 20 IPs, comma separated
+	Expire      string `json:"expire,omitempty"`      // -1, 30, 90, 180, 360 (days), -1 as default
+}
+
+// SubApiKeyCreateResponse Response of POST /api/v1/sub/api-key
+type SubApiKeyCreateResponse struct {
+	BaseResponse
+	Data SubApiKeyData `json:"data"`
+}
+
+// SubApiKeyUpdateRequest Request of POST /api/v1/sub/api-key/update
+type SubApiKeyUpdateRequest struct {
+	SubName     string `json:"subName"`
+	ApiKey      string `json:"apiKey"`
+	Passphrase  string `json:"passphrase"`
+	Permission  string `json:"permission,omitempty"`
+	IpWhitelist string `json:"ipWhitelist,omitempty"`
+	Expire      string `json:"expire,omitempty"`
+}
+
+// SubApiKeyUpdateResponse Response of POST /api/v1/sub/api-key/update
+type SubApiKeyUpdateResponse struct {
+	BaseResponse
+	Data SubApiKeyUpdateData `json:"data"`
+}
+type SubApiKeyUpdateData struct {
+	SubName     string `json:"subName"`
+	ApiKey      string `json:"apiKey"`
+	Permission  string `json:"permission"`
+	IpWhitelist string `json:"ipWhitelist"`
+}
+
+// SubApiKeyDeleteResponse Response of DELETE /api/v1/sub/api-key
+type SubApiKeyDeleteResponse struct {
+	BaseResponse
+	Data SubApiKeyDeleteData `json:"data"`
+}
+type SubApiKeyDeleteData struct {
+	SubName string `json:"subName"`
+	ApiKey  string `json:"apiKey"`
+}
+
+// SubTransferRequest Request of POST /api/v2/accounts/sub-transfer
+type SubTransferRequest struct {
+	ClientOid      string          `json:"clientOid"`
+	Currency       string          `json:"currency"`
+	Amount         decimal.Decimal `json:"amount"`
+	Direction      string          `json:"direction"`                // OUT (master to sub) or IN (sub to master)
+	AccountType    string          `json:"accountType,omitempty"`    // MAIN, TRADE, MARGIN or CONTRACT, MAIN as default
+	SubAccountType string          `json:"subAccountType,omitempty"` // MAIN, TRADE, MARGIN or CONTRACT, MAIN as default
+	SubUserId      string          `json:"subUserId"`
+}
+
+// SubTransferResponse Response of POST /api/v2/accounts/sub-transfer
+type SubTransferResponse struct {
+	BaseResponse
+	Data SubTransferData `json:"data"`
+}
+type SubTransferData struct {
+	OrderId string `json:"orderId"`
+}
+
+// DepositAddressRequest Request of POST /api/v1/deposit-addresses
+type DepositAddressRequest struct {
+	Currency string `json:"currency"`
+	Chain    string `json:"chain,omitempty"` // e.g. ERC20, TRC20, BEP20. The default chain of the currency is used if empty
+}
+
+// DepositAddressResponse Response of POST /api/v1/deposit-addresses and GET /api/v1/deposit-addresses
+type DepositAddressResponse struct {
+	BaseResponse
+	Data DepositAddressData `json:"data"`
+}
+
+// DepositAddressListResponse Response of GET /api/v2/deposit-addresses
+type DepositAddressListResponse struct {
+	BaseResponse
+	Data []DepositAddressData `json:"data"`
+}
+type DepositAddressData struct {
+	Address         string `json:"address"`
+	Memo            string `json:"memo"`
+	Chain           string `json:"chain"`
+	ContractAddress string `json:"contractAddress"`
+}
+
+// DepositAddressV3Response Response of GET /api/v3/deposit-addresses
+type DepositAddressV3Response struct {
+	BaseResponse
+	Data []DepositAddressV3Data `json:"data"`
+}
+type DepositAddressV3Data struct {
+	Address         string `json:"address"`
+	Memo            string `json:"memo"`
+	ChainId         string `json:"chainId"`
+	ChainName       string `json:"chainName"`
+	To              string `json:"to"` // MAIN or TRADE
+	ExpirationDate  int64  `json:"expirationDate"`
+	Currency        string `json:"currency"`
+	ContractAddress string `json:"contractAddress"`
+}
+
+// DepositListRequest Request of GET /api/v1/deposits
+type DepositListRequest struct {
+	Currency string `json:"currency"` // [Optional]
+	StartAt  int64  `json:"startAt"`  // [Optional] Start time (millisecond)
+	EndAt    int64  `json:"endAt"`    // [Optional] End time (millisecond)
+	Status   string `json:"status"`   // [Optional] PROCESSING, SUCCESS or FAILURE
+}
+
+// DepositListResponse Response of GET /api/v1/deposits
+type DepositListResponse struct {
+	BaseResponse
+	Data DepositListData `json:"data"`
+}
+type DepositListData struct {
+	BaseResponsePagination
+	Items []DepositItem `json:"items"`
+}
+type DepositItem struct {
+	Currency   string          `json:"currency"`
+	Chain      string          `json:"chain"`
+	Status     string          `json:"status"` // PROCESSING, SUCCESS or FAILURE
+	Address    string          `json:"address"`
+	Memo       string          `json:"memo"`
+	IsInner    bool            `json:"isInner"`
+	Amount     decimal.Decimal `json:"amount"`
+	Fee        decimal.Decimal `json:"fee"`
+	WalletTxId string          `json:"walletTxId"`
+	Remark     string          `json:"remark"`
+	CreatedAt  int64           `json:"createdAt"`
+	UpdatedAt  int64           `json:"updatedAt"`
+}
+
+// WithdrawalQuotasResponse Response of GET /api/v1/withdrawals/quotas
+type WithdrawalQuotasResponse struct {
+	BaseResponse
+	Data WithdrawalQuotasData `json:"data"`
+}
+type WithdrawalQuotasData struct {
+	Currency                 string          `json:"currency"`
+	Chain                    string          `json:"chain"`
+	LimitBTCAmount           decimal.Decimal `json:"limitBTCAmount"`
+	UsedBTCAmount            decimal.Decimal `json:"usedBTCAmount"`
+	QuotaCurrency            string          `json:"quotaCurrency"`
+	LimitQuotaCurrencyAmount decimal.Decimal `json:"limitQuotaCurrencyAmount"`
+	UsedQuotaCurrencyAmount  decimal.Decimal `json:"usedQuotaCurrencyAmount"`
+	RemainAmount             decimal.Decimal `json:"remainAmount"`
+	AvailableAmount          decimal.Decimal `json:"availableAmount"`
+	WithdrawMinFee           decimal.Decimal `json:"withdrawMinFee"`
+	InnerWithdrawMinFee      decimal.Decimal `json:"innerWithdrawMinFee"`
+	WithdrawMinSize          decimal.Decimal `json:"withdrawMinSize"`
+	IsWithdrawEnabled        bool            `json:"isWithdrawEnabled"`
+	Precision                int32           `json:"precision"`
+	Reason                   string          `json:"reason"`
+	LockedAmount             decimal.Decimal `json:"lockedAmount"`
+}
+
+// WithdrawRequest Request of POST /api/v1/withdrawals
+type WithdrawRequest struct {
+	Currency      string          `json:"currency"`
+	Address       string          `json:"address"`
+	Amount        decimal.Decimal `json:"amount"`
+	Memo          string          `json:"memo,omitempty"`
+	IsInner       bool            `json:"isInner,omitempty"` // Internal withdrawal to another KuCoin account
+	Remark        string          `json:"remark,omitempty"`
+	Chain         string          `json:"chain,omitempty"`         // e.g. ERC20, TRC20, BEP20. The default chain of the currency is used if empty
+	FeeDeductType string          `json:"feeDeductType,omitempty"` // INTERNAL (deduct from main account) or EXTERNAL (deduct from withdrawal amount)
+}
+
+// WithdrawResponse Response of POST /api/v1/withdrawals
+type WithdrawResponse struct {
+	BaseResponse
+	Data WithdrawData `json:"data"`
+}
+type WithdrawData struct {
+	WithdrawalId string `json:"withdrawalId"`
+}
+
+// WithdrawalListRequest Request of GET /api/v1/withdrawals
+type WithdrawalListRequest struct {
+	Currency string `json:"currency"` // [Optional]
+	StartAt  int64  `json:"startAt"`  // [Optional] Start time (millisecond)
+	EndAt    int64  `json:"endAt"`    // [Optional] End time (millisecond)
+	Status   string `json:"status"`   // [Optional] PROCESSING, WALLET_PROCESSING, SUCCESS or FAILURE
+}
+
+// WithdrawalListResponse Response of GET /api/v1/withdrawals
+type WithdrawalListResponse struct {
+	BaseResponse
+	Data WithdrawalListData `json:"data"`
+}
+type WithdrawalListData struct {
+	BaseResponsePagination
+	Items []WithdrawalItem `json:"items"`
+}
+type WithdrawalItem struct {
+	Id         string          `json:"id"`
+	Currency   string          `json:"currency"`
+	Chain      string          `json:"chain"`
+	Status     string          `json:"status"` // PROCESSING, WALLET_PROCESSING, SUCCESS or FAILURE
+	Address    string          `json:"address"`
+	Memo       string          `json:"memo"`
+	IsInner    bool            `json:"isInner"`
+	Amount     decimal.Decimal `json:"amount"`
+	Fee        decimal.Decimal `json:"fee"`
+	WalletTxId string          `json:"walletTxId"`
+	Remark     string          `json:"remark"`
+	CreatedAt  int64           `json:"createdAt"`
+	UpdatedAt  int64           `json:"updatedAt"`
+}
+
+// BulletResponse Response of POST /api/v1/bullet-public and POST /api/v1/bullet-private
+type BulletResponse struct {
+	BaseResponse
+	Data BulletData `json:"data"`
+}
+type BulletData struct {
+	Token           string               `json:"token"`
+	InstanceServers []InstanceServerData `json:"instanceServers"`
+}
+type InstanceServerData struct {
+	Endpoint     string `json:"endpoint"` // WebSocket server address
+	Protocol     string `json:"protocol"` // websocket
+	Encrypt      bool   `json:"encrypt"`
+	PingInterval int64  `json:"pingInterval"` // Interval of sending ping to the server (millisecond)
+	PingTimeout  int64  `json:"pingTimeout"`  // The connection is closed if no pong is received within it (millisecond)
+}
+
+// WebSocket message types
+const (
+	WsTypeWelcome     = "welcome"
+	WsTypePing        = "ping"
+	WsTypePong        = "pong"
+	WsTypeSubscribe   = "subscribe"
+	WsTypeUnsubscribe = "unsubscribe"
+	WsTypeAck         = "ack"
+	WsTypeMessage     = "message"
+	WsTypeError       = "error"
+	WsTypeOpenTunnel  = "openTunnel"
+	WsTypeCloseTunnel = "closeTunnel"
+)
+
+// WebSocket topics of the spot market
+const (
+	TopicSpotTicker        = "/market/ticker:%s"            // Symbols separated by commas, or all
+	TopicSpotSnapshot      = "/market/snapshot:%s"          // Symbol or market
+	TopicSpotLevel2        = "/market/level2:%s"            // Symbols separated by commas
+	TopicSpotLevel2Depth5  = "/spotMarket/level2Depth5:%s"  // Symbols separated by commas
+	TopicSpotLevel2Depth50 = "/spotMarket/level2Depth50:%s" // Symbols separated by commas
+	TopicSpotMatch         = "/market/match:%s"             // Symbols separated by commas
+	TopicSpotCandles       = "/market/candles:%s_%s"        // Symbol and candle type, e.g. BTC-USDT_1hour
+	TopicSpotLevel3        = "/spotMarket/level3:%s"        // Symbols separated by commas
+
+	TopicSpotTradeOrders    = "/spotMarket/tradeOrders"
+	TopicSpotTradeOrdersV2  = "/spotMarket/tradeOrdersV2"
+	TopicSpotBalance        = "/account/balance"
+	TopicSpotAdvancedOrders = "/spotMarket/advancedOrders"
+	TopicSpotMarginPosition = "/margin/position"
+)
+
+// WebSocket topics of the future market
+const (
+	TopicFutureTickerV2      = "/contractMarket/tickerV2:%s"
+	TopicFutureLevel2        = "/contractMarket/level2:%s"
+	TopicFutureLevel2Depth5  = "/contractMarket/level2Depth5:%s"
+	TopicFutureLevel2Depth50 = "/contractMarket/level2Depth50:%s"
+	TopicFutureExecution     = "/contractMarket/execution:%s"
+	TopicFutureInstrument    = "/contract/instrument:%s"
+	TopicFutureAnnouncement  = "/contract/announcement:%s"
+
+	TopicFutureTradeOrders    = "/contractMarket/tradeOrders"
+	TopicFuturePosition       = "/contract/position:%s"
+	TopicFutureWallet         = "/contractAccount/wallet"
+	TopicFutureAdvancedOrders = "/contractMarket/advancedOrders"
+)
+
+// Subjects of the future topics
+const (
+	SubjectMarkIndexPrice     = "mark.index.price"
+	SubjectFundingRate        = "funding.rate"
+	SubjectFundingBegin       = "funding.begin"
+	SubjectFundingEnd         = "funding.end"
+	SubjectPositionChange     = "position.change"
+	SubjectPositionSettlement = "position.settlement"
+	SubjectOrderMargin        = "orderMargin.change"
+	SubjectAvailableBalance   = "availableBalance.change"
+	SubjectWithdrawHold       = "withdrawHold.change"
+)
+
+// Subjects of /spotMarket/level3
+const (
+	SubjectLevel3Received = "received"
+	SubjectLevel3Open     = "open"
+	SubjectLevel3Update   = "update"
+	SubjectLevel3Match    = "match"
+	SubjectLevel3Done     = "done"
+)
+
+// Type of order events
+const (
+	OrderEventReceived = "received" // Only in /spotMarket/tradeOrdersV2
+	OrderEventOpen     = "open"
+	OrderEventMatch    = "match"
+	OrderEventFilled   = "filled"
+	OrderEventCanceled = "canceled"
+	OrderEventUpdate   = "update"
+)
+
+// Type of stop order events
+const (
+	StopOrderEventOpen      = "open"
+	StopOrderEventTriggered = "triggered"
+	StopOrderEventCancel    = "cancel"
+)
+
+// WsMessage Message exchanged with the WebSocket server
+type WsMessage struct {
+	Id             string          `json:"id,omitempty"`
+	Type           string          `json:"type"`
+	Topic          string          `json:"topic,omitempty"`
+	Subject        string          `json:"subject,omitempty"`
+	ChannelType    string          `json:"channelType,omitempty"` // public, private or session
+	PrivateChannel bool            `json:"privateChannel,omitempty"`
+	Response       bool            `json:"response,omitempty"`
+	Code           int             `json:"code,omitempty"` // Only in error messages
+	TunnelId       string          `json:"tunnelId,omitempty"`
+	NewTunnelId    string          `json:"newTunnelId,omitempty"` // Only in openTunnel messages
+	Data           json.RawMessage `json:"data,omitempty"`
+}
+
+// OrderBookLevel A price level of the order book, decoded from ["price","size"] or ["price","size","sequence"]
+type OrderBookLevel struct {
+	Price    decimal.Decimal
+	Size     decimal.Decimal
+	Sequence int64 // Only in level2 changes
+}
+
+func (l *OrderBookLevel) UnmarshalJSON(b []byte) error {
+	var v []decimal.Decimal
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(v) < 2 {
+		return fmt.Errorf("invalid order book level %s", b)
+	}
+	l.Price, l.Size = v[0], v[1]
+	if len(v) > 2 {
+		l.Sequence = v[2].IntPart()
+	}
+	return nil
+}
+
+func (l OrderBookLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string{l.Price.String(), l.Size.String()})
+}
+
+// Candle A candlestick, decoded from ["time","open","close","high","low","volume","turnover"]
+type Candle struct {
+	Time     int64 // Start time of the candle (second)
+	Open     decimal.Decimal
+	Close    decimal.Decimal
+	High     decimal.Decimal
+	Low      decimal.Decimal
+	Volume   decimal.Decimal // Transaction volume
+	Turnover decimal.Decimal // Transaction amount
+}
+
+func (c *Candle) UnmarshalJSON(b []byte) error {
+	var v []decimal.Decimal
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(v) < 7 {
+		return fmt.Errorf("invalid candle %s", b)
+	}
+	c.Time = v[0].IntPart()
+	c.Open, c.Close, c.High, c.Low, c.Volume, c.Turnover = v[1], v[2], v[3], v[4], v[5], v[6]
+	return nil
+}
+
+func (c Candle) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string{
+		strconv.FormatInt(c.Time, 10), c.Open.String(), c.Close.String(), c.High.String(),
+		c.Low.String(), c.Volume.String(), c.Turnover.String(),
+	})
+}
+
+// SpotTickerResponse Response of GET /api/v1/market/orderbook/level1
+type SpotTickerResponse struct {
+	BaseResponse
+	Data SpotTickerData `json:"data"`
+}
+type SpotTickerData struct {
+	Sequence    string          `json:"sequence"`
+	Price       decimal.Decimal `json:"price"` // Last traded price
+	Size        decimal.Decimal `json:"size"`  // Last traded size
+	BestBid     decimal.Decimal `json:"bestBid"`
+	BestBidSize decimal.Decimal `json:"bestBidSize"`
+	BestAsk     decimal.Decimal `json:"bestAsk"`
+	BestAskSize decimal.Decimal `json:"bestAskSize"`
+	Time        int64           `json:"time"` // millisecond
+}
+
+// SpotAllTickersResponse Response of GET /api/v1/market/allTickers
+type SpotAllTickersResponse struct {
+	BaseResponse
+	Data SpotAllTickersData `json:"data"`
+}
+type SpotAllTickersData struct {
+	Time   int64            `json:"time"` // millisecond
+	Ticker []SpotTickerItem `json:"ticker"`
+}
+type SpotTickerItem struct {
+	Symbol           string          `json:"symbol"`
+	SymbolName       string          `json:"symbolName"`
+	Buy              decimal.Decimal `json:"buy"`  // Best bid
+	Sell             decimal.Decimal `json:"sell"` // Best ask
+	ChangeRate       decimal.Decimal `json:"changeRate"`
+	ChangePrice      decimal.Decimal `json:"changePrice"`
+	High             decimal.Decimal `json:"high"`
+	Low              decimal.Decimal `json:"low"`
+	Vol              decimal.Decimal `json:"vol"`
+	VolValue         decimal.Decimal `json:"volValue"`
+	Last             decimal.Decimal `json:"last"` // Last traded price
+	AveragePrice     decimal.Decimal `json:"averagePrice"`
+	TakerFeeRate     decimal.Decimal `json:"takerFeeRate"`
+	MakerFeeRate     decimal.Decimal `json:"makerFeeRate"`
+	TakerCoefficient decimal.Decimal `json:"takerCoefficient"`
+	MakerCoefficient decimal.Decimal `json:"makerCoefficient"`
+}
+
+// SpotKlinesResponse Response of GET /api/v1/market/candles
+type SpotKlinesResponse struct {
+	BaseResponse
+	Data []Candle `json:"data"`
+}
+
+// FutureKlinesResponse Response of GET /api/v1/kline/query
+type FutureKlinesResponse struct {
+	BaseResponse
+	Data []FutureCandle `json:"data"`
+}
+
+// FutureCandle A future candlestick, decoded from [time,open,high,low,close,volume]
+type FutureCandle struct {
+	Time   int64 // Start time of the candle (millisecond)
+	Open   decimal.Decimal
+	High   decimal.Decimal
+	Low    decimal.Decimal
+	Close  decimal.Decimal
+	Volume decimal.Decimal // Transaction volume (Cont)
+}
+
+func (c *FutureCandle) UnmarshalJSON(b []byte) error {
+	var v []decimal.Decimal
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(v) < 6 {
+		return fmt.Errorf("invalid candle %s", b)
+	}
+	c.Time = v[0].IntPart()
+	c.Open, c.High, c.Low, c.Close, c.Volume = v[1], v[2], v[3], v[4], v[5]
+	return nil
+}
+
+func (c FutureCandle) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{
+		c.Time, c.Open, c.High, c.Low, c.Close, c.Volume,
+	})
+}
+
+// SpotTickerEvent Data of /market/ticker:{symbols}
+type SpotTickerEvent struct {
+	Symbol      string          `json:"symbol"`
+	Sequence    int64           `json:"sequence,string"`
+	Price       decimal.Decimal `json:"price"`
+	Size        decimal.Decimal `json:"size"`
+	BestAsk     decimal.Decimal `json:"bestAsk"`
+	BestAskSize decimal.Decimal `json:"bestAskSize"`
+	BestBid     decimal.Decimal `json:"bestBid"`
+	BestBidSize decimal.Decimal `json:"bestBidSize"`
+	Time        int64           `json:"time"` // millisecond
+}
+
+// SpotSnapshotEvent Data of /market/snapshot:{symbol or market}
+type SpotSnapshotEvent struct {
+	Sequence int64            `json:"sequence,string"`
+	Data     SpotSnapshotData `json:"data"`
+}
+type SpotSnapshotData struct {
+	Symbol           string          `json:"symbol"`
+	SymbolCode       string          `json:"symbolCode"`
+	BaseCurrency     string          `json:"baseCurrency"`
+	QuoteCurrency    string          `json:"quoteCurrency"`
+	Market           string          `json:"market"`
+	Markets          []string        `json:"markets"`
+	Trading          bool            `json:"trading"`
+	MarginTrade      bool            `json:"marginTrade"`
+	AveragePrice     decimal.Decimal `json:"averagePrice"`
+	Buy              decimal.Decimal `json:"buy"`  // Best bid price
+	Sell             decimal.Decimal `json:"sell"` // Best ask price
+	LastTradedPrice  decimal.Decimal `json:"lastTradedPrice"`
+	Open             decimal.Decimal `json:"open"`
+	Close            decimal.Decimal `json:"close"`
+	High             decimal.Decimal `json:"high"`
+	Low              decimal.Decimal `json:"low"`
+	ChangePrice      decimal.Decimal `json:"changePrice"`
+	ChangeRate       decimal.Decimal `json:"changeRate"`
+	Vol              decimal.Decimal `json:"vol"`      // 24h volume
+	VolValue         decimal.Decimal `json:"volValue"` // 24h turnover
+	MakerFeeRate     decimal.Decimal `json:"makerFeeRate"`
+	TakerFeeRate     decimal.Decimal `json:"takerFeeRate"`
+	MakerCoefficient decimal.Decimal `json:"makerCoefficient"`
+	TakerCoefficient decimal.Decimal `json:"takerCoefficient"`
+	Datetime         int64           `json:"datetime"` // millisecond
+}
+
+// SpotLevel2Event Data of /market/level2:{symbols}
+type SpotLevel2Event struct {
+	Symbol        string            `json:"symbol"`
+	SequenceStart int64             `json:"sequenceStart"`
+	SequenceEnd   int64             `json:"sequenceEnd"`
+	Changes       SpotLevel2Changes `json:"changes"`
+	Time          int64             `json:"time"` // millisecond
+}
+type SpotLevel2Changes struct {
+	Asks []OrderBookLevel `json:"asks"`
+	Bids []OrderBookLevel `json:"bids"`
+}
+
+// SpotDepthEvent Data of /spotMarket/level2Depth5:{symbols} and /spotMarket/level2Depth50:{symbols}
+type SpotDepthEvent struct {
+	Symbol    string           `json:"symbol"`
+	Asks      []OrderBookLevel `json:"asks"`
+	Bids      []OrderBookLevel `json:"bids"`
+	Timestamp int64            `json:"timestamp"` // millisecond
+}
+
+// SpotMatchEvent Data of /market/match:{symbols}
+type SpotMatchEvent struct {
+	Sequence     int64           `json:"sequence,string"`
+	Type         string          `json:"type"`
+	Symbol       string          `json:"symbol"`
+	Side         string          `json:"side"` // Taker side, buy or sell
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	TradeId      string          `json:"tradeId"`
+	TakerOrderId string          `json:"takerOrderId"`
+	MakerOrderId string          `json:"makerOrderId"`
+	Time         int64           `json:"time,string"` // nanosecond
+}
+
+// SpotCandleEvent Data of /market/candles:{symbol}_{type}
+type SpotCandleEvent struct {
+	Symbol  string `json:"symbol"`
+	Candles Candle `json:"candles"`
+	Time    int64  `json:"time"` // nanosecond
+}
+
+// SpotOrderEvent Data of /spotMarket/tradeOrders and /spotMarket/tradeOrdersV2
+type SpotOrderEvent struct {
+	Symbol       string          `json:"symbol"`
+	OrderType    string          `json:"orderType"` // limit or market
+	Side         string          `json:"side"`
+	OrderId      string          `json:"orderId"`
+	ClientOid    string          `json:"clientOid"`
+	Type         string          `json:"type"`      // received, open, match, filled, canceled or update
+	Status       string          `json:"status"`    // new, open, match or done
+	OrderTime    int64           `json:"orderTime"` // nanosecond
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	Funds        decimal.Decimal `json:"funds"` // Market order by funds only
+	FilledSize   decimal.Decimal `json:"filledSize"`
+	RemainSize   decimal.Decimal `json:"remainSize"`
+	RemainFunds  decimal.Decimal `json:"remainFunds"`
+	CanceledSize decimal.Decimal `json:"canceledSize"`
+	OriginSize   decimal.Decimal `json:"originSize"`
+	OldSize      decimal.Decimal `json:"oldSize"`    // Only in update events
+	Liquidity    string          `json:"liquidity"`  // Only in match events, taker or maker
+	MatchPrice   decimal.Decimal `json:"matchPrice"` // Only in match events
+	MatchSize    decimal.Decimal `json:"matchSize"`  // Only in match events
+	TradeId      string          `json:"tradeId"`    // Only in match events
+	Ts           int64           `json:"ts"`         // nanosecond
+}
+
+// SpotBalanceEvent Data of /account/balance
+type SpotBalanceEvent struct {
+	AccountId       string                  `json:"accountId"`
+	Currency        string                  `json:"currency"`
+	Total           decimal.Decimal         `json:"total"`
+	Available       decimal.Decimal         `json:"available"`
+	AvailableChange decimal.Decimal         `json:"availableChange"`
+	Hold            decimal.Decimal         `json:"hold"`
+	HoldChange      decimal.Decimal         `json:"holdChange"`
+	RelationEvent   string                  `json:"relationEvent"` // e.g. trade.hold, trade.setted, main.deposit
+	RelationEventId string                  `json:"relationEventId"`
+	RelationContext SpotBalanceEventContext `json:"relationContext"`
+	Time            int64                   `json:"time,string"` // millisecond
+}
+type SpotBalanceEventContext struct {
+	Symbol  string `json:"symbol"`
+	TradeId string `json:"tradeId"`
+	OrderId string `json:"orderId"`
+}
+
+// SpotAdvancedOrderEvent Data of /spotMarket/advancedOrders
+type SpotAdvancedOrderEvent struct {
+	Symbol     string          `json:"symbol"`
+	OrderId    string          `json:"orderId"`
+	OrderType  string          `json:"orderType"` // stop
+	Type       string          `json:"type"`      // open, triggered or cancel
+	Side       string          `json:"side"`
+	Size       decimal.Decimal `json:"size"`
+	OrderPrice decimal.Decimal `json:"orderPrice"`
+	Stop       string          `json:"stop"` // loss or entry
+	StopPrice  decimal.Decimal `json:"stopPrice"`
+	TradeType  string          `json:"tradeType"`
+	CreatedAt  int64           `json:"createdAt"` // millisecond
+	Ts         int64           `json:"ts"`        // nanosecond
+}
+
+// SpotMarginPositionEvent Data of /margin/position.
+// Subject debt.ratio carries the debt fields, subject position.status carries Type
+type SpotMarginPositionEvent struct {
+	Subject   string                     `json:"-"`
+	DebtRatio decimal.Decimal            `json:"debtRatio"`
+	TotalDebt decimal.Decimal            `json:"totalDebt"` // In BTC
+	DebtList  map[string]decimal.Decimal `json:"debtList"`  // Debt of each currency
+	Type      string                     `json:"type"`      // e.g. FROZEN_FL, UNFROZEN_FL, FROZEN_BANKRUPTCY
+	Timestamp int64                      `json:"timestamp"` // millisecond
+}
+
+// FutureTickerEvent Data of /contractMarket/tickerV2:{symbol}
+type FutureTickerEvent struct {
+	Symbol       string          `json:"symbol"`
+	Sequence     int64           `json:"sequence"`
+	BestBidPrice decimal.Decimal `json:"bestBidPrice"`
+	BestBidSize  int             `json:"bestBidSize"` // Cont
+	BestAskPrice decimal.Decimal `json:"bestAskPrice"`
+	BestAskSize  int             `json:"bestAskSize"` // Cont
+	Ts           int64           `json:"ts"`          // nanosecond
+}
+
+// FutureLevel2Event Data of /contractMarket/level2:{symbol}.
+// Change is "price,side,size", which is also parsed into Price, Side and Size
+type FutureLevel2Event struct {
+	Symbol    string          `json:"-"`
+	Sequence  int64           `json:"sequence"`
+	Change    string          `json:"change"`
+	Price     decimal.Decimal `json:"-"`
+	Side      string          `json:"-"`         // buy or sell
+	Size      int             `json:"-"`         // Cont, 0 means the price level is removed
+	Timestamp int64           `json:"timestamp"` // millisecond
+}
+
+// FutureDepthEvent Data of /contractMarket/level2Depth5:{symbol} and /contractMarket/level2Depth50:{symbol}
+type FutureDepthEvent struct {
+	Symbol    string           `json:"-"`
+	Sequence  int64            `json:"sequence"`
+	Asks      []OrderBookLevel `json:"asks"`
+	Bids      []OrderBookLevel `json:"bids"`
+	Ts        int64            `json:"ts"`        // millisecond
+	Timestamp int64            `json:"timestamp"` // millisecond
+}
+
+// FutureExecutionEvent Data of /contractMarket/execution:{symbol}
+type FutureExecutionEvent struct {
+	Symbol       string          `json:"symbol"`
+	Sequence     int64           `json:"sequence"`
+	Side         string          `json:"side"` // Taker side, buy or sell
+	Price        decimal.Decimal `json:"price"`
+	Size         int             `json:"size"` // Cont
+	TradeId      string          `json:"tradeId"`
+	TakerOrderId string          `json:"takerOrderId"`
+	MakerOrderId string          `json:"makerOrderId"`
+	Ts           int64           `json:"ts"` // nanosecond
+}
+
+// FutureInstrumentEvent Data of /contract/instrument:{symbol}.
+// Subject mark.index.price carries MarkPrice and IndexPrice, subject funding.rate carries FundingRate
+type FutureInstrumentEvent struct {
+	Symbol      string          `json:"-"`
+	Subject     string          `json:"-"`
+	Granularity int64           `json:"granularity"` // millisecond
+	MarkPrice   decimal.Decimal `json:"markPrice"`
+	IndexPrice  decimal.Decimal `json:"indexPrice"`
+	FundingRate decimal.Decimal `json:"fundingRate"`
+	Timestamp   int64           `json:"timestamp"` // millisecond
+}
+
+// FutureAnnouncementEvent Data of /contract/announcement:{symbol}. Subject is funding.begin or funding.end
+type FutureAnnouncementEvent struct {
+	Symbol      string          `json:"symbol"`
+	Subject     string          `json:"-"`
+	FundingTime int64           `json:"fundingTime"` // millisecond
+	FundingRate decimal.Decimal `json:"fundingRate"`
+	Timestamp   int64           `json:"timestamp"` // millisecond
+}
+
+// FutureOrderEvent Data of /contractMarket/tradeOrders
+type FutureOrderEvent struct {
+	OrderId      string          `json:"orderId"`
+	Symbol       string          `json:"symbol"`
+	ClientOid    string          `json:"clientOid"`
+	Type         string          `json:"type"`      // open, match, filled, canceled or update
+	Status       string          `json:"status"`    // open, match or done
+	OrderType    string          `json:"orderType"` // limit or market
+	Side         string          `json:"side"`
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"` // Cont
+	FilledSize   decimal.Decimal `json:"filledSize"`
+	RemainSize   decimal.Decimal `json:"remainSize"`
+	CanceledSize decimal.Decimal `json:"canceledSize"`
+	OldSize      decimal.Decimal `json:"oldSize"`    // Only in update events
+	Liquidity    string          `json:"liquidity"`  // Only in match events, taker or maker
+	MatchPrice   decimal.Decimal `json:"matchPrice"` // Only in match events
+	MatchSize    decimal.Decimal `json:"matchSize"`  // Only in match events
+	TradeId      string          `json:"tradeId"`    // Only in match events
+	OrderTime    int64           `json:"orderTime"`  // nanosecond
+	Ts           int64           `json:"ts"`         // nanosecond
+}
+
+// FuturePositionEvent Data of /contract/position:{symbol}.
+// Subject position.change carries the fields of FuturePositionData, only the mark price related fields
+// are set if ChangeReason is markPriceChange. Subject position.settlement carries the funding fields.
+type FuturePositionEvent struct {
+	FuturePositionData
+	Subject      string          `json:"-"`
+	ChangeReason string          `json:"changeReason"` // e.g. positionChange, markPriceChange
+	FundingTime  int64           `json:"fundingTime"`  // millisecond
+	Qty          int             `json:"qty"`          // Position size at funding settlement
+	FundingRate  decimal.Decimal `json:"fundingRate"`
+	FundingFee   decimal.Decimal `json:"fundingFee"`
+	Ts           int64           `json:"ts"` // millisecond
+}
+
+// FutureWalletEvent Data of /contractAccount/wallet.
+// Subject orderMargin.change carries OrderMargin, availableBalance.change carries AvailableBalance and HoldBalance,
+// withdrawHold.change carries WithdrawHold
+type FutureWalletEvent struct {
+	Subject          string          `json:"-"`
+	Currency         string          `json:"currency"`
+	OrderMargin      decimal.Decimal `json:"orderMargin"`
+	AvailableBalance decimal.Decimal `json:"availableBalance"`
+	HoldBalance      decimal.Decimal `json:"holdBalance"`
+	WithdrawHold     decimal.Decimal `json:"withdrawHold"`
+	Timestamp        int64           `json:"timestamp"` // millisecond
+}
+
+// FutureAdvancedOrderEvent Data of /contractMarket/advancedOrders
+type FutureAdvancedOrderEvent struct {
+	OrderId        string          `json:"orderId"`
+	Symbol         string          `json:"symbol"`
+	Type           string          `json:"type"`      // open, triggered or cancel
+	OrderType      string          `json:"orderType"` // stop
+	Side           string          `json:"side"`
+	Size           decimal.Decimal `json:"size"` // Cont
+	OrderPrice     decimal.Decimal `json:"orderPrice"`
+	Stop           string          `json:"stop"` // up or down
+	StopPrice      decimal.Decimal `json:"stopPrice"`
+	StopPriceType  string          `json:"stopPriceType"` // TP, IP or MP
+	TriggerSuccess bool            `json:"triggerSuccess"`
+	Error          string          `json:"error"`     // Reason of trigger failure
+	CreatedAt      int64           `json:"createdAt"` // millisecond
+	Ts             int64           `json:"ts"`        // nanosecond
+}
+
+// OrderBookOrder An order of the level3 order book, decoded from ["orderId","price","size",time]
+type OrderBookOrder struct {
+	OrderId string
+	Side    Side // buy or sell
+	Price   decimal.Decimal
+	Size    decimal.Decimal
+	Time    int64 // Time of the order entering the book (nanosecond)
+}
+
+func (o *OrderBookOrder) UnmarshalJSON(b []byte) error {
+	var v []json.RawMessage
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(v) < 4 {
+		return fmt.Errorf("invalid order book order %s", b)
+	}
+	var t decimal.Decimal
+	if err := json.Unmarshal(v[0], &o.OrderId); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(v[1], &o.Price); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(v[2], &o.Size); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(v[3], &t); err != nil {
+		return err
+	}
+	o.Time = t.IntPart()
+	return nil
+}
+
+// SpotLevel3Event Data of /spotMarket/level3:{symbols}. The fields set depend on Subject:
+// received carries OrderId and ClientOid, open carries the order, update carries the new Size,
+// match carries the trade and done carries Reason
+type SpotLevel3Event struct {
+	Subject      string          `json:"-"`
+	Symbol       string          `json:"symbol"`
+	Sequence     int64           `json:"sequence,string"`
+	OrderId      string          `json:"orderId"`
+	ClientOid    string          `json:"clientOid"`
+	Side         Side            `json:"side"`
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	RemainSize   decimal.Decimal `json:"remainSize"` // Only in match events, remaining size of the maker order
+	TakerOrderId string          `json:"takerOrderId"`
+	MakerOrderId string          `json:"makerOrderId"`
+	TradeId      string          `json:"tradeId"`
+	Reason       string          `json:"reason"`           // Only in done events, filled or canceled
+	OrderTime    int64           `json:"orderTime,string"` // nanosecond
+	Ts           int64           `json:"ts,string"`        // nanosecond
+}
@@ -0,0 +1,1013 @@
+package kugo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWsClosed is returned when using a WsClient after Close.
+var ErrWsClosed = errors.New("websocket client is closed")
+
+// WsHandler Handle a message pushed by the server. Handlers are called one at a time
+// in the order the messages are received, so they must not block.
+type WsHandler func(msg *WsMessage)
+
+type WsOption func(c *WsClient) error
+
+const (
+	wsMaxTopics           = 300  // Topics of a connection
+	wsSymbolsPerSubscribe = 100  // Symbols of a subscribe message
+	wsEventBuffer         = 1024 // Messages received and not yet passed to the handlers
+)
+
+// WsClient Long-lived WebSocket connections to KuCoin.
+// Subscriptions are sharded across connections, or multiplex tunnels of a connection,
+// so that none of them exceeds the topic limit. Messages of every shard are fanned in to
+// a single queue and passed to the handlers of the topics from one goroutine. Each connection is kept alive with ping messages and,
+// when it is lost, a new token is applied, the connection is reestablished with backoff
+// and its topics are subscribed again.
+type WsClient struct {
+	seq int64
+
+	bullet      func() (*BulletData, error)
+	ackTimeout  time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	errLog      func(...interface{})
+	dialTimeout time.Duration
+	maxTopics   int // Topics of a shard
+	tunnels     int // Tunnels of a connection, 0 to shard by connection only
+
+	mu      sync.Mutex
+	started bool
+	links   []*wsLink
+	subs    map[string]*wsSubscription // Key is the subscribed topic
+	routes  map[string][]*wsSubscription
+	closed  chan struct{}
+
+	events      chan *WsMessage // Messages of every connection, in the order they are received
+	dispatching sync.Once
+}
+
+type wsSubscription struct {
+	topic   string
+	private bool
+	handler WsHandler
+	parts   []*wsPart
+}
+
+// wsPart A part of a subscription subscribed on a shard.
+// Topics with more symbols than a subscribe message accepts are split into parts
+type wsPart struct {
+	topic   string
+	private bool
+	size    int // Number of symbols
+	shard   *wsShard
+}
+
+// wsLink A connection and its shards. It is reconnected independently of the other links
+type wsLink struct {
+	conn       *wsConn
+	shards     []*wsShard
+	reconnects int
+	lastErr    error
+	removed    bool
+}
+
+// wsShard A tunnel of a link, or the link itself if tunnels are disabled
+type wsShard struct {
+	link     *wsLink
+	tunnelId string
+	conn     *wsConn // Set after the tunnel is opened on the connection of the link
+	parts    map[*wsPart]struct{}
+	topics   int
+}
+
+// WsConnStats Health of a connection of WsClient
+type WsConnStats struct {
+	ConnectId   string
+	Connected   bool
+	Tunnels     []string
+	Topics      int           // Number of subscribed symbols
+	Messages    int64         // Number of received messages of the current connection
+	Reconnects  int           // Number of reconnections
+	ConnectedAt time.Time     // Time of the current connection
+	LastMessage time.Time     // Time of the last received message
+	Latency     time.Duration // Round trip time of the last ping
+	LastError   string        // Error of the last disconnection
+}
+
+// NewSpotWsClient Create a WebSocket client of the spot market.
+// If private is true, the token is applied with the API key and private topics can be subscribed.
+func (kc *Kucoin) NewSpotWsClient(private bool, opts ...WsOption) (*WsClient, error) {
+	if private {
+		return NewWsClient(kc.SpotBulletPrivate, opts...)
+	}
+	return NewWsClient(kc.SpotBulletPublic, opts...)
+}
+
+// NewFutureWsClient Create a WebSocket client of the future market.
+// If private is true, the token is applied with the API key and private topics can be subscribed.
+func (kc *Kucoin) NewFutureWsClient(private bool, opts ...WsOption) (*WsClient, error) {
+	if private {
+		return NewWsClient(kc.FutureBulletPrivate, opts...)
+	}
+	return NewWsClient(kc.FutureBulletPublic, opts...)
+}
+
+// NewWsClient Create a WebSocket client which applies for tokens with bullet
+func NewWsClient(bullet func() (*BulletData, error), opts ...WsOption) (*WsClient, error) {
+	if bullet == nil {
+		return nil, errors.New("bullet is nil")
+	}
+	c := &WsClient{
+		bullet:      bullet,
+		ackTimeout:  10 * time.Second,
+		minBackoff:  time.Second,
+		maxBackoff:  time.Minute,
+		errLog:      defaultLog,
+		dialTimeout: 10 * time.Second,
+		maxTopics:   wsMaxTopics,
+		subs:        make(map[string]*wsSubscription),
+		routes:      make(map[string][]*wsSubscription),
+		closed:      make(chan struct{}),
+		events:      make(chan *WsMessage, wsEventBuffer),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// SetWsAckTimeout Set the time to wait for the server to acknowledge a subscription
+func SetWsAckTimeout(timeout time.Duration) WsOption {
+	return func(c *WsClient) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		c.ackTimeout = timeout
+		return nil
+	}
+}
+
+// SetWsReconnectBackoff Set the wait between reconnection attempts.
+// The wait starts at min and doubles after every failed attempt, up to max
+func SetWsReconnectBackoff(min, max time.Duration) WsOption {
+	return func(c *WsClient) error {
+		if min <= 0 || max < min {
+			return errors.New("invalid backoff")
+		}
+		c.minBackoff = min
+		c.maxBackoff = max
+		return nil
+	}
+}
+
+// SetWsErrorLog Set the printing method of connection errors
+func SetWsErrorLog(l func(...interface{})) WsOption {
+	return func(c *WsClient) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		c.errLog = l
+		return nil
+	}
+}
+
+// SetWsMaxTopics Set the maximum number of topics of a connection, or of a tunnel if tunnels are enabled.
+// Every symbol counts, e.g. /market/ticker:BTC-USDT,ETH-USDT counts as 2. It is 300 by default
+func SetWsMaxTopics(n int) WsOption {
+	return func(c *WsClient) error {
+		if n <= 0 {
+			return errors.New("max topics must be positive")
+		}
+		c.maxTopics = n
+		return nil
+	}
+}
+
+// SetWsTunnels Shard the subscriptions across up to n multiplex tunnels of a connection
+// before opening another connection. Tunnels are disabled by default
+func SetWsTunnels(n int) WsOption {
+	return func(c *WsClient) error {
+		if n < 0 {
+			return errors.New("tunnels must not be negative")
+		}
+		c.tunnels = n
+		return nil
+	}
+}
+
+// Connect Apply for tokens, connect to the server and subscribe to the registered topics.
+// The connections are kept alive until Close is called.
+func (c *WsClient) Connect() error {
+	if c.isClosed() {
+		return ErrWsClosed
+	}
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return errors.New("websocket client is already connected")
+	}
+	c.started = true
+	c.dispatching.Do(func() { go c.dispatchLoop() })
+	if len(c.links) == 0 {
+		c.newShard()
+	}
+	links := make([]*wsLink, len(c.links))
+	copy(links, c.links)
+	c.mu.Unlock()
+
+	conns := make([]*wsConn, 0, len(links))
+	for _, link := range links {
+		conn, err := c.startLink(link)
+		if err != nil {
+			c.mu.Lock()
+			c.started = false
+			c.mu.Unlock()
+			for i, conn := range conns {
+				conn.close(err)
+				c.detach(links[i], conn)
+			}
+			return err
+		}
+		conns = append(conns, conn)
+	}
+	for i, conn := range conns {
+		go c.supervise(links[i], conn)
+	}
+	return nil
+}
+
+// Close Close the connections and stop reconnecting
+func (c *WsClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+	close(c.closed)
+	for _, link := range c.links {
+		if link.conn != nil {
+			link.conn.close(ErrWsClosed)
+			c.detachLocked(link, link.conn)
+		}
+	}
+	return nil
+}
+
+// Subscribe Subscribe to the topic, e.g. /market/ticker:BTC-USDT,ETH-USDT.
+// Messages of every symbol of the topic are passed to handler. Set private to true for private topics.
+// Topics with many symbols are split and sharded across the connections.
+// If the client is not connected yet, the topic is subscribed when Connect is called.
+func (c *WsClient) Subscribe(topic string, private bool, handler WsHandler) error {
+	if handler == nil {
+		return errors.New("handler is nil")
+	}
+	if c.isClosed() {
+		return ErrWsClosed
+	}
+
+	sub := &wsSubscription{topic: topic, private: private, handler: handler}
+	c.mu.Lock()
+	if _, ok := c.subs[topic]; ok {
+		c.mu.Unlock()
+		return fmt.Errorf("topic %s is already subscribed", topic)
+	}
+	c.subs[topic] = sub
+	for _, route := range wsRoutes(topic) {
+		c.routes[route] = append(c.routes[route], sub)
+	}
+
+	max := c.maxTopics
+	if max > wsSymbolsPerSubscribe {
+		max = wsSymbolsPerSubscribe
+	}
+	links := len(c.links)
+	var newLinks []*wsLink
+	var newShards []*wsShard
+	var parts []*wsPart
+	for _, part := range wsParts(topic, private, max) {
+		created := c.place(part)
+		sub.parts = append(sub.parts, part)
+		switch {
+		case !created:
+			// The part is subscribed by attach if the shard is not ready yet
+			if part.shard.conn != nil {
+				parts = append(parts, part)
+			}
+		case len(c.links) > links && c.links[len(c.links)-1] == part.shard.link && !containsLink(newLinks, part.shard.link):
+			newLinks = append(newLinks, part.shard.link)
+		case part.shard.link.conn != nil:
+			newShards = append(newShards, part.shard)
+		}
+	}
+	started := c.started
+	c.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	err := c.subscribe(newLinks, newShards, parts)
+	if err != nil {
+		c.Unsubscribe(topic)
+	}
+	return err
+}
+
+// subscribe Connect the new links, open the new tunnels and subscribe to the parts on the ready shards
+func (c *WsClient) subscribe(newLinks []*wsLink, newShards []*wsShard, parts []*wsPart) error {
+	for _, link := range newLinks {
+		conn, err := c.startLink(link)
+		if err != nil {
+			return err
+		}
+		go c.supervise(link, conn)
+	}
+	for _, shard := range newShards {
+		c.mu.Lock()
+		conn := shard.link.conn
+		c.mu.Unlock()
+		if conn == nil {
+			continue
+		}
+		if err := c.openShard(conn, shard); err != nil {
+			return err
+		}
+	}
+	for _, part := range parts {
+		c.mu.Lock()
+		conn, tunnelId := part.shard.conn, part.shard.tunnelId
+		c.mu.Unlock()
+		if conn == nil {
+			continue
+		}
+		if err := conn.subscribe(part, tunnelId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsLink(links []*wsLink, link *wsLink) bool {
+	for _, l := range links {
+		if l == link {
+			return true
+		}
+	}
+	return false
+}
+
+// Unsubscribe Unsubscribe from the topic. It must be the same as the subscribed one.
+// Tunnels and connections left without topics are closed
+func (c *WsClient) Unsubscribe(topic string) error {
+	type unsubscribe struct {
+		conn     *wsConn
+		tunnelId string
+		part     *wsPart
+	}
+	var unsubscribes []unsubscribe
+	var tunnels []*wsShard
+	var conns []*wsConn
+
+	c.mu.Lock()
+	sub := c.remove(topic)
+	if sub == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("topic %s is not subscribed", topic)
+	}
+	for _, part := range sub.parts {
+		shard := part.shard
+		if shard.conn != nil {
+			unsubscribes = append(unsubscribes, unsubscribe{conn: shard.conn, tunnelId: shard.tunnelId, part: part})
+		}
+		delete(shard.parts, part)
+		shard.topics -= part.size
+		if len(shard.parts) == 0 {
+			tunnel, conn := c.release(shard)
+			if tunnel != nil {
+				tunnels = append(tunnels, tunnel)
+			}
+			if conn != nil {
+				conns = append(conns, conn)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	var err error
+	for _, u := range unsubscribes {
+		if _, e := u.conn.request(&WsMessage{
+			Type:           WsTypeUnsubscribe,
+			Topic:          u.part.topic,
+			PrivateChannel: u.part.private,
+			TunnelId:       u.tunnelId,
+			Response:       true,
+		}); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, shard := range tunnels {
+		if _, e := shard.conn.request(&WsMessage{Type: WsTypeCloseTunnel, TunnelId: shard.tunnelId, Response: true}); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, conn := range conns {
+		conn.close(errWsLinkRemoved)
+	}
+	return err
+}
+
+// Topics Return the subscribed topics
+func (c *WsClient) Topics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topics := make([]string, 0, len(c.subs))
+	for topic := range c.subs {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// Stats Return the health of every connection
+func (c *WsClient) Stats() []WsConnStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make([]WsConnStats, 0, len(c.links))
+	for _, link := range c.links {
+		s := WsConnStats{Reconnects: link.reconnects}
+		if link.lastErr != nil {
+			s.LastError = link.lastErr.Error()
+		}
+		for _, shard := range link.shards {
+			if shard.tunnelId != "" {
+				s.Tunnels = append(s.Tunnels, shard.tunnelId)
+			}
+			s.Topics += shard.topics
+		}
+		if conn := link.conn; conn != nil {
+			s.ConnectId = conn.connectId
+			s.Connected = !conn.isDone()
+			s.Messages = atomic.LoadInt64(&conn.messages)
+			s.ConnectedAt = conn.connectedAt
+			s.LastMessage = time.Unix(0, atomic.LoadInt64(&conn.lastRecv))
+			s.Latency = time.Duration(atomic.LoadInt64(&conn.rtt))
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// remove Remove the subscription of the topic. c.mu must be held
+func (c *WsClient) remove(topic string) *wsSubscription {
+	sub, ok := c.subs[topic]
+	if !ok {
+		return nil
+	}
+	delete(c.subs, topic)
+	for _, route := range wsRoutes(topic) {
+		list := c.routes[route]
+		for i := range list {
+			if list[i] == sub {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(list) == 0 {
+			delete(c.routes, route)
+		} else {
+			c.routes[route] = list
+		}
+	}
+	return sub
+}
+
+// place Put the part on the first shard with room for it, or on a new shard.
+// Return whether the shard is created. c.mu must be held
+func (c *WsClient) place(part *wsPart) bool {
+	var shard *wsShard
+	created := false
+	for _, link := range c.links {
+		for _, s := range link.shards {
+			if s.topics+part.size <= c.maxTopics {
+				shard = s
+				break
+			}
+		}
+		if shard != nil {
+			break
+		}
+	}
+	if shard == nil {
+		shard = c.newShard()
+		created = true
+	}
+	shard.parts[part] = struct{}{}
+	shard.topics += part.size
+	part.shard = shard
+	return created
+}
+
+// newShard Open a tunnel on a link with room for it, or a new link. c.mu must be held
+func (c *WsClient) newShard() *wsShard {
+	shard := &wsShard{parts: make(map[*wsPart]struct{})}
+	if c.tunnels > 0 {
+		shard.tunnelId = "bt" + c.nextId()
+		for _, link := range c.links {
+			if len(link.shards) < c.tunnels {
+				shard.link = link
+				link.shards = append(link.shards, shard)
+				return shard
+			}
+		}
+	}
+	link := &wsLink{shards: []*wsShard{shard}}
+	shard.link = link
+	c.links = append(c.links, link)
+	return shard
+}
+
+// release Remove the empty shard unless it is the last one of the client.
+// Return the tunnel to close, or the connection to close if the link is left without shards. c.mu must be held
+func (c *WsClient) release(shard *wsShard) (*wsShard, *wsConn) {
+	if len(c.links) == 1 && len(c.links[0].shards) == 1 {
+		return nil, nil
+	}
+	link := shard.link
+	for i, s := range link.shards {
+		if s == shard {
+			link.shards = append(link.shards[:i], link.shards[i+1:]...)
+			break
+		}
+	}
+	if len(link.shards) != 0 {
+		if shard.tunnelId != "" && shard.conn != nil {
+			return shard, nil
+		}
+		return nil, nil
+	}
+
+	link.removed = true
+	for i, l := range c.links {
+		if l == link {
+			c.links = append(c.links[:i], c.links[i+1:]...)
+			break
+		}
+	}
+	return nil, link.conn
+}
+
+// wsRoutes Split a topic into the topics of the pushed messages.
+// e.g. /market/ticker:BTC-USDT,ETH-USDT is pushed as /market/ticker:BTC-USDT and /market/ticker:ETH-USDT
+func wsRoutes(topic string) []string {
+	i := strings.Index(topic, ":")
+	if i < 0 {
+		return []string{topic}
+	}
+	prefix, symbols := topic[:i+1], strings.Split(topic[i+1:], ",")
+	routes := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		routes = append(routes, prefix+strings.TrimSpace(symbol))
+	}
+	return routes
+}
+
+// wsParts Split a topic into parts of at most max symbols
+func wsParts(topic string, private bool, max int) []*wsPart {
+	i := strings.Index(topic, ":")
+	if i < 0 {
+		return []*wsPart{{topic: topic, private: private, size: 1}}
+	}
+	prefix, symbols := topic[:i+1], strings.Split(topic[i+1:], ",")
+	if len(symbols) <= max {
+		return []*wsPart{{topic: topic, private: private, size: len(symbols)}}
+	}
+	parts := make([]*wsPart, 0, (len(symbols)+max-1)/max)
+	for start := 0; start < len(symbols); start += max {
+		end := start + max
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		parts = append(parts, &wsPart{topic: prefix + strings.Join(symbols[start:end], ","), private: private, size: end - start})
+	}
+	return parts
+}
+
+// push Queue a message of a connection for dispatchLoop.
+// It blocks while the queue is full, so a slow handler holds back the connections
+func (c *WsClient) push(msg *WsMessage) {
+	select {
+	case c.events <- msg:
+	case <-c.closed:
+	}
+}
+
+// dispatchLoop Pass the queued messages to the handlers one at a time until the client is closed
+func (c *WsClient) dispatchLoop() {
+	for {
+		select {
+		case <-c.closed:
+			return
+		case msg := <-c.events:
+			c.dispatch(msg)
+		}
+	}
+}
+
+func (c *WsClient) dispatch(msg *WsMessage) {
+	c.mu.Lock()
+	subs := c.routes[msg.Topic]
+	if len(subs) == 0 {
+		// Private topics like /contractMarket/tradeOrders are pushed with the symbol appended
+		if i := strings.Index(msg.Topic, ":"); i >= 0 {
+			subs = c.routes[msg.Topic[:i]]
+		}
+	}
+	handlers := make([]WsHandler, 0, len(subs))
+	for _, sub := range subs {
+		handlers = append(handlers, sub.handler)
+	}
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
+
+func (c *WsClient) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *WsClient) nextId() string {
+	return strconv.FormatInt(atomic.AddInt64(&c.seq, 1), 10)
+}
+
+func (c *WsClient) logError(v ...interface{}) {
+	if c.errLog != nil {
+		c.errLog(v...)
+	}
+}
+
+// errWsLinkRemoved is returned when reconnecting a link whose topics are all unsubscribed
+var errWsLinkRemoved = errors.New("connection is no longer used")
+
+// startLink Connect the link and subscribe to its topics
+func (c *WsClient) startLink(link *wsLink) (*wsConn, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.attach(link, conn); err != nil {
+		conn.close(err)
+		return nil, err
+	}
+	return conn, nil
+}
+
+// attach Make conn the connection of the link, then open the tunnels and subscribe to the topics of the link
+func (c *WsClient) attach(link *wsLink, conn *wsConn) error {
+	c.mu.Lock()
+	if c.isClosed() {
+		c.mu.Unlock()
+		return ErrWsClosed
+	}
+	if link.removed {
+		c.mu.Unlock()
+		return errWsLinkRemoved
+	}
+	link.conn = conn
+	shards := make([]*wsShard, len(link.shards))
+	copy(shards, link.shards)
+	c.mu.Unlock()
+
+	for _, shard := range shards {
+		if err := c.openShard(conn, shard); err != nil {
+			c.detach(link, conn)
+			return err
+		}
+	}
+	return nil
+}
+
+// openShard Open the tunnel of the shard on conn and subscribe to its topics
+func (c *WsClient) openShard(conn *wsConn, shard *wsShard) error {
+	if shard.tunnelId != "" {
+		if _, err := conn.request(&WsMessage{Type: WsTypeOpenTunnel, NewTunnelId: shard.tunnelId, Response: true}); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	if shard.link.conn != conn {
+		c.mu.Unlock()
+		return nil
+	}
+	shard.conn = conn
+	parts := make([]*wsPart, 0, len(shard.parts))
+	for part := range shard.parts {
+		parts = append(parts, part)
+	}
+	c.mu.Unlock()
+
+	for _, part := range parts {
+		if err := conn.subscribe(part, shard.tunnelId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *WsClient) detach(link *wsLink, conn *wsConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.detachLocked(link, conn)
+}
+
+// detachLocked c.mu must be held
+func (c *WsClient) detachLocked(link *wsLink, conn *wsConn) {
+	if link.conn != conn {
+		return
+	}
+	link.conn = nil
+	for _, shard := range link.shards {
+		if shard.conn == conn {
+			shard.conn = nil
+		}
+	}
+}
+
+// supervise Reconnect the link when the connection is lost, until the client is closed or the link is removed
+func (c *WsClient) supervise(link *wsLink, conn *wsConn) {
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-conn.done:
+		}
+		c.mu.Lock()
+		link.lastErr = conn.err
+		removed := link.removed
+		c.mu.Unlock()
+		c.detach(link, conn)
+		if removed {
+			return
+		}
+		c.logError(fmt.Sprintf("info:websocket_disconnected\tconnectId:%s\terror:%v", conn.connectId, conn.err))
+
+		conn = c.reconnect(link)
+		if conn == nil {
+			return
+		}
+		c.mu.Lock()
+		link.reconnects++
+		c.mu.Unlock()
+	}
+}
+
+func (c *WsClient) reconnect(link *wsLink) *wsConn {
+	wait := c.minBackoff
+	for {
+		conn, err := c.startLink(link)
+		if err == nil {
+			return conn
+		}
+		if err == ErrWsClosed || err == errWsLinkRemoved {
+			return nil
+		}
+		c.logError(fmt.Sprintf("info:websocket_reconnect\terror:%v\tretry_in:%s", err, wait))
+
+		select {
+		case <-c.closed:
+			return nil
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > c.maxBackoff {
+			wait = c.maxBackoff
+		}
+	}
+}
+
+// dial Apply for a token, connect to the first instance server and wait for the welcome message
+func (c *WsClient) dial() (*wsConn, error) {
+	if c.isClosed() {
+		return nil, ErrWsClosed
+	}
+	bullet, err := c.bullet()
+	if err != nil {
+		return nil, err
+	}
+	if len(bullet.InstanceServers) == 0 {
+		return nil, errors.New("no instance server available")
+	}
+	server := bullet.InstanceServers[0]
+
+	connectId := c.nextId()
+	endpoint := fmt.Sprintf("%s?token=%s&connectId=%s", server.Endpoint, bullet.Token, connectId)
+	origin := strings.Replace(strings.Replace(server.Endpoint, "wss://", "https://", 1), "ws://", "http://", 1)
+	config, err := websocket.NewConfig(endpoint, origin)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server sends a welcome message once the connection is established
+	welcome := &WsMessage{}
+	ws.SetReadDeadline(time.Now().Add(c.dialTimeout))
+	if err = websocket.JSON.Receive(ws, welcome); err != nil {
+		ws.Close()
+		return nil, err
+	}
+	if welcome.Type != WsTypeWelcome {
+		ws.Close()
+		return nil, fmt.Errorf("unexpected message %s, want welcome", welcome.Type)
+	}
+	ws.SetReadDeadline(time.Time{})
+
+	conn := &wsConn{
+		connectId:    connectId,
+		connectedAt:  time.Now(),
+		client:       c,
+		ws:           ws,
+		pending:      make(map[string]chan *WsMessage),
+		pingInterval: time.Duration(server.PingInterval) * time.Millisecond,
+		pingTimeout:  time.Duration(server.PingTimeout) * time.Millisecond,
+		lastRecv:     time.Now().UnixNano(),
+		done:         make(chan struct{}),
+	}
+	go conn.read()
+	go conn.ping()
+	return conn, nil
+}
+
+// wsConn A single WebSocket connection
+type wsConn struct {
+	lastRecv int64 // Time of the last received message (unix nanosecond)
+	messages int64 // Number of received messages
+	rtt      int64 // Round trip time of the last ping (nanosecond)
+	pingId   int64 // Id of the last ping
+	pingAt   int64 // Time of the last ping (unix nanosecond)
+
+	connectId    string
+	connectedAt  time.Time
+	client       *WsClient
+	ws           *websocket.Conn
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+
+	pendingMu sync.Mutex
+	pending   map[string]chan *WsMessage // Requests waiting for ack, keyed by id
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func (conn *wsConn) close(err error) {
+	conn.closeOnce.Do(func() {
+		conn.err = err
+		close(conn.done)
+		conn.ws.Close()
+	})
+}
+
+func (conn *wsConn) isDone() bool {
+	select {
+	case <-conn.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (conn *wsConn) read() {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn.ws, &data); err != nil {
+			conn.close(err)
+			return
+		}
+		atomic.StoreInt64(&conn.lastRecv, time.Now().UnixNano())
+
+		msg := &WsMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			conn.client.logError(fmt.Sprintf("info:websocket_message\terror:%v\tmessage:%s", err, data))
+			continue
+		}
+		switch msg.Type {
+		case WsTypeMessage:
+			atomic.AddInt64(&conn.messages, 1)
+			conn.client.push(msg)
+		case WsTypePong:
+			if id, err := strconv.ParseInt(msg.Id, 10, 64); err == nil && id == atomic.LoadInt64(&conn.pingId) {
+				atomic.StoreInt64(&conn.rtt, time.Now().UnixNano()-atomic.LoadInt64(&conn.pingAt))
+			}
+		case WsTypeAck, WsTypeError:
+			conn.pendingMu.Lock()
+			ch, ok := conn.pending[msg.Id]
+			delete(conn.pending, msg.Id)
+			conn.pendingMu.Unlock()
+			if ok {
+				ch <- msg
+			} else if msg.Type == WsTypeError {
+				conn.client.logError(fmt.Sprintf("info:websocket_error\tcode:%d\tdata:%s", msg.Code, msg.Data))
+			}
+		}
+	}
+}
+
+// ping Send ping messages every ping interval, and close the connection
+// if nothing is received within ping interval + ping timeout
+func (conn *wsConn) ping() {
+	if conn.pingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(conn.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+		last := time.Unix(0, atomic.LoadInt64(&conn.lastRecv))
+		if time.Since(last) > conn.pingInterval+conn.pingTimeout {
+			conn.close(errors.New("ping timeout"))
+			return
+		}
+		id := atomic.AddInt64(&conn.client.seq, 1)
+		atomic.StoreInt64(&conn.pingAt, time.Now().UnixNano())
+		atomic.StoreInt64(&conn.pingId, id)
+		if err := conn.send(&WsMessage{Id: strconv.FormatInt(id, 10), Type: WsTypePing}); err != nil {
+			conn.close(err)
+			return
+		}
+	}
+}
+
+func (conn *wsConn) send(msg *WsMessage) error {
+	conn.ws.SetWriteDeadline(time.Now().Add(conn.client.ackTimeout))
+	return websocket.JSON.Send(conn.ws, msg)
+}
+
+func (conn *wsConn) subscribe(part *wsPart, tunnelId string) error {
+	_, err := conn.request(&WsMessage{
+		Type:           WsTypeSubscribe,
+		Topic:          part.topic,
+		PrivateChannel: part.private,
+		TunnelId:       tunnelId,
+		Response:       true,
+	})
+	return err
+}
+
+// request Send the message and wait for the ack of the server
+func (conn *wsConn) request(msg *WsMessage) (*WsMessage, error) {
+	msg.Id = conn.client.nextId()
+	ch := make(chan *WsMessage, 1)
+	conn.pendingMu.Lock()
+	conn.pending[msg.Id] = ch
+	conn.pendingMu.Unlock()
+	defer func() {
+		conn.pendingMu.Lock()
+		delete(conn.pending, msg.Id)
+		conn.pendingMu.Unlock()
+	}()
+
+	if err := conn.send(msg); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(conn.client.ackTimeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		if reply.Type == WsTypeError {
+			return nil, fmt.Errorf("%s %s: %d %s", msg.Type, msg.Topic, reply.Code, reply.Data)
+		}
+		return reply, nil
+	case <-conn.done:
+		return nil, conn.err
+	case <-timer.C:
+		return nil, fmt.Errorf("%s %s: ack timeout", msg.Type, msg.Topic)
+	}
+}
@@ -0,0 +1,166 @@
+package kugo
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
+)
+
+// SubscribeFutureTicker Subscribe to /contractMarket/tickerV2:{symbols}
+func (c *WsClient) SubscribeFutureTicker(handler func(*FutureTickerEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFutureTickerV2, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureTickerEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureLevel2 Subscribe to /contractMarket/level2:{symbols}
+func (c *WsClient) SubscribeFutureLevel2(handler func(*FutureLevel2Event), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFutureLevel2, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureLevel2Event{}
+		if !c.decode(msg, event) {
+			return
+		}
+		if err := event.parseChange(); err != nil {
+			c.logError(fmt.Sprintf("info:websocket_decode\ttopic:%s\terror:%v\tdata:%s", msg.Topic, err, msg.Data))
+			return
+		}
+		event.Symbol = wsSymbol(msg)
+		handler(event)
+	})
+}
+
+// parseChange Parse the change "price,side,size"
+func (e *FutureLevel2Event) parseChange() error {
+	fields := strings.Split(e.Change, ",")
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid change %s", e.Change)
+	}
+	price, err := decimal.NewFromString(fields[0])
+	if err != nil {
+		return err
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return err
+	}
+	e.Price, e.Side, e.Size = price, fields[1], size
+	return nil
+}
+
+// SubscribeFutureDepth5 Subscribe to /contractMarket/level2Depth5:{symbols}
+func (c *WsClient) SubscribeFutureDepth5(handler func(*FutureDepthEvent), symbols ...string) error {
+	return c.subscribeFutureDepth(TopicFutureLevel2Depth5, handler, symbols)
+}
+
+// SubscribeFutureDepth50 Subscribe to /contractMarket/level2Depth50:{symbols}
+func (c *WsClient) SubscribeFutureDepth50(handler func(*FutureDepthEvent), symbols ...string) error {
+	return c.subscribeFutureDepth(TopicFutureLevel2Depth50, handler, symbols)
+}
+
+func (c *WsClient) subscribeFutureDepth(format string, handler func(*FutureDepthEvent), symbols []string) error {
+	topic := fmt.Sprintf(format, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureDepthEvent{}
+		if c.decode(msg, event) {
+			event.Symbol = wsSymbol(msg)
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureExecution Subscribe to /contractMarket/execution:{symbols}
+func (c *WsClient) SubscribeFutureExecution(handler func(*FutureExecutionEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFutureExecution, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureExecutionEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureInstrument Subscribe to /contract/instrument:{symbols} for mark price, index price and funding rate
+func (c *WsClient) SubscribeFutureInstrument(handler func(*FutureInstrumentEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFutureInstrument, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureInstrumentEvent{}
+		if c.decode(msg, event) {
+			event.Symbol = wsSymbol(msg)
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureAnnouncement Subscribe to /contract/announcement:{symbols} for the beginning and end of funding settlement
+func (c *WsClient) SubscribeFutureAnnouncement(handler func(*FutureAnnouncementEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFutureAnnouncement, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &FutureAnnouncementEvent{}
+		if c.decode(msg, event) {
+			if len(event.Symbol) == 0 {
+				event.Symbol = wsSymbol(msg)
+			}
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureOrders Subscribe to /contractMarket/tradeOrders. Orders of all symbols are pushed if symbols is empty.
+// A private token is required
+func (c *WsClient) SubscribeFutureOrders(handler func(*FutureOrderEvent), symbols ...string) error {
+	topic := TopicFutureTradeOrders
+	if len(symbols) != 0 {
+		topic = topic + ":" + strings.Join(symbols, ",")
+	}
+	return c.Subscribe(topic, true, func(msg *WsMessage) {
+		event := &FutureOrderEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFuturePosition Subscribe to /contract/position:{symbols}. A private token is required
+func (c *WsClient) SubscribeFuturePosition(handler func(*FuturePositionEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicFuturePosition, strings.Join(symbols, ","))
+	return c.Subscribe(topic, true, func(msg *WsMessage) {
+		event := &FuturePositionEvent{}
+		if c.decode(msg, event) {
+			if len(event.Symbol) == 0 {
+				event.Symbol = wsSymbol(msg)
+			}
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureWallet Subscribe to /contractAccount/wallet. A private token is required
+func (c *WsClient) SubscribeFutureWallet(handler func(*FutureWalletEvent)) error {
+	return c.Subscribe(TopicFutureWallet, true, func(msg *WsMessage) {
+		event := &FutureWalletEvent{}
+		if c.decode(msg, event) {
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}
+
+// SubscribeFutureAdvancedOrders Subscribe to /contractMarket/advancedOrders for stop order events.
+// A private token is required
+func (c *WsClient) SubscribeFutureAdvancedOrders(handler func(*FutureAdvancedOrderEvent)) error {
+	return c.Subscribe(TopicFutureAdvancedOrders, true, func(msg *WsMessage) {
+		event := &FutureAdvancedOrderEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
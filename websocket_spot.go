@@ -0,0 +1,171 @@
+package kugo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SubscribeSpotTicker Subscribe to /market/ticker:{symbols}. Use "all" as the only symbol for all symbols
+func (c *WsClient) SubscribeSpotTicker(handler func(*SpotTickerEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicSpotTicker, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotTickerEvent{}
+		if c.decode(msg, event) {
+			event.Symbol = wsSymbol(msg)
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotSnapshot Subscribe to /market/snapshot:{symbol}. A market like BTC can be used instead of a symbol
+func (c *WsClient) SubscribeSpotSnapshot(handler func(*SpotSnapshotEvent), symbol string) error {
+	topic := fmt.Sprintf(TopicSpotSnapshot, symbol)
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotSnapshotEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotLevel2 Subscribe to /market/level2:{symbols}
+func (c *WsClient) SubscribeSpotLevel2(handler func(*SpotLevel2Event), symbols ...string) error {
+	topic := fmt.Sprintf(TopicSpotLevel2, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotLevel2Event{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotDepth5 Subscribe to /spotMarket/level2Depth5:{symbols}
+func (c *WsClient) SubscribeSpotDepth5(handler func(*SpotDepthEvent), symbols ...string) error {
+	return c.subscribeSpotDepth(TopicSpotLevel2Depth5, handler, symbols)
+}
+
+// SubscribeSpotDepth50 Subscribe to /spotMarket/level2Depth50:{symbols}
+func (c *WsClient) SubscribeSpotDepth50(handler func(*SpotDepthEvent), symbols ...string) error {
+	return c.subscribeSpotDepth(TopicSpotLevel2Depth50, handler, symbols)
+}
+
+func (c *WsClient) subscribeSpotDepth(format string, handler func(*SpotDepthEvent), symbols []string) error {
+	topic := fmt.Sprintf(format, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotDepthEvent{}
+		if c.decode(msg, event) {
+			event.Symbol = wsSymbol(msg)
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotMatch Subscribe to /market/match:{symbols}
+func (c *WsClient) SubscribeSpotMatch(handler func(*SpotMatchEvent), symbols ...string) error {
+	topic := fmt.Sprintf(TopicSpotMatch, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotMatchEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotCandles Subscribe to /market/candles:{symbol}_{type}.
+// candleType is one of 1min, 3min, 15min, 30min, 1hour, 2hour, 4hour, 6hour, 8hour, 12hour, 1day, 1week
+func (c *WsClient) SubscribeSpotCandles(handler func(*SpotCandleEvent), symbol, candleType string) error {
+	topic := fmt.Sprintf(TopicSpotCandles, symbol, candleType)
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotCandleEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotLevel3 Subscribe to /spotMarket/level3:{symbols}
+func (c *WsClient) SubscribeSpotLevel3(handler func(*SpotLevel3Event), symbols ...string) error {
+	topic := fmt.Sprintf(TopicSpotLevel3, strings.Join(symbols, ","))
+	return c.Subscribe(topic, false, func(msg *WsMessage) {
+		event := &SpotLevel3Event{}
+		if c.decode(msg, event) {
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}
+
+// decode Unmarshal the data of the message into v. Errors are logged and the message is dropped
+func (c *WsClient) decode(msg *WsMessage, v interface{}) bool {
+	if err := json.Unmarshal(msg.Data, v); err != nil {
+		c.logError(fmt.Sprintf("info:websocket_decode\ttopic:%s\terror:%v\tdata:%s", msg.Topic, err, msg.Data))
+		return false
+	}
+	return true
+}
+
+// wsSymbol Return the symbol of the message. Messages of the "all" topics carry the symbol in the subject
+func wsSymbol(msg *WsMessage) string {
+	i := strings.LastIndex(msg.Topic, ":")
+	if i < 0 {
+		return ""
+	}
+	symbol := msg.Topic[i+1:]
+	if symbol == "all" {
+		return msg.Subject
+	}
+	return symbol
+}
+
+// SubscribeSpotOrders Subscribe to /spotMarket/tradeOrders. A private token is required
+func (c *WsClient) SubscribeSpotOrders(handler func(*SpotOrderEvent)) error {
+	return c.subscribeSpotOrders(TopicSpotTradeOrders, handler)
+}
+
+// SubscribeSpotOrdersV2 Subscribe to /spotMarket/tradeOrdersV2, which also pushes received events.
+// A private token is required
+func (c *WsClient) SubscribeSpotOrdersV2(handler func(*SpotOrderEvent)) error {
+	return c.subscribeSpotOrders(TopicSpotTradeOrdersV2, handler)
+}
+
+func (c *WsClient) subscribeSpotOrders(topic string, handler func(*SpotOrderEvent)) error {
+	return c.Subscribe(topic, true, func(msg *WsMessage) {
+		event := &SpotOrderEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotBalance Subscribe to /account/balance. A private token is required
+func (c *WsClient) SubscribeSpotBalance(handler func(*SpotBalanceEvent)) error {
+	return c.Subscribe(TopicSpotBalance, true, func(msg *WsMessage) {
+		event := &SpotBalanceEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotAdvancedOrders Subscribe to /spotMarket/advancedOrders for stop order events.
+// A private token is required
+func (c *WsClient) SubscribeSpotAdvancedOrders(handler func(*SpotAdvancedOrderEvent)) error {
+	return c.Subscribe(TopicSpotAdvancedOrders, true, func(msg *WsMessage) {
+		event := &SpotAdvancedOrderEvent{}
+		if c.decode(msg, event) {
+			handler(event)
+		}
+	})
+}
+
+// SubscribeSpotMarginPosition Subscribe to /margin/position. A private token is required
+func (c *WsClient) SubscribeSpotMarginPosition(handler func(*SpotMarginPositionEvent)) error {
+	return c.Subscribe(TopicSpotMarginPosition, true, func(msg *WsMessage) {
+		event := &SpotMarginPositionEvent{}
+		if c.decode(msg, event) {
+			event.Subject = msg.Subject
+			handler(event)
+		}
+	})
+}